@@ -0,0 +1,78 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/stavinski/gowac/scanner"
+	"github.com/stavinski/gowac/utils"
+)
+
+// Reads the URLs already checked from a checkpoint file, a missing file has no URLs
+func readCheckpoint(filename string) (map[string]struct{}, error) {
+	done := map[string]struct{}{}
+	f, err := os.Open(filename)
+	if os.IsNotExist(err) {
+		return done, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		if raw := strings.TrimSpace(lines.Text()); len(raw) > 0 {
+			done[raw] = struct{}{}
+		}
+	}
+	return done, lines.Err()
+}
+
+// Skips the targets that have already been checked, stops once ctx is done
+func skipCheckpointed(ctx context.Context, in <-chan scanner.Target, done map[string]struct{}) <-chan scanner.Target {
+	return utils.Filter(ctx, in, func(t scanner.Target) bool {
+		_, ok := done[t.URL]
+		return !ok
+	})
+}
+
+// Appends the URL of each result to the checkpoint file before writing, errors are not
+// recorded so they are checked again on resume, each URL is written straight to the file so progress survives a crash
+// a URL with more than one result such as one per profile is only recorded once all of them have not errored
+type checkpointWriter struct {
+	mu  sync.Mutex
+	w   resultWriter
+	f   *os.File
+	per int
+	// results that have not errored for the URLs not yet recorded
+	checked map[string]int
+}
+
+func (c *checkpointWriter) Write(r scanner.Result) error {
+	if r.Result == scanner.ResultError {
+		return c.w.Write(r)
+	}
+	c.mu.Lock()
+	var err error
+	if c.checked[r.URL]++; c.checked[r.URL] >= c.per {
+		delete(c.checked, r.URL)
+		_, err = fmt.Fprintln(c.f, r.URL)
+	}
+	c.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return c.w.Write(r)
+}
+
+func (c *checkpointWriter) Close() error {
+	if err := c.w.Close(); err != nil {
+		return err
+	}
+	return c.f.Close()
+}
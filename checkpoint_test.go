@@ -0,0 +1,47 @@
+package main
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// A URL checked with several profiles is only recorded once none of them errored
+func TestCheckpointProfiles(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "checkpoint.txt")
+	f, err := os.Create(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	w := &checkpointWriter{w: &ndjsonWriter{enc: json.NewEncoder(io.Discard)}, f: f, per: 2, checked: map[string]int{}}
+
+	results := []scanner.Result{
+		{URL: "https://example.com/admin", Profile: "user", Result: scanner.ResultDenied},
+		{URL: "https://example.com/admin", Profile: "admin", Result: scanner.ResultGranted},
+		{URL: "https://example.com/users", Profile: "user", Result: scanner.ResultError},
+		{URL: "https://example.com/users", Profile: "admin", Result: scanner.ResultGranted},
+		{URL: "https://example.com/logs", Profile: "user", Result: scanner.ResultDenied},
+	}
+	for _, r := range results {
+		if err := w.Write(r); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	done, err := readCheckpoint(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]struct{}{"https://example.com/admin": {}}
+	if !reflect.DeepEqual(done, want) {
+		t.Errorf("expected %v got %v", want, done)
+	}
+}
@@ -0,0 +1,93 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Responses with the same status and normalized body
+type cluster struct {
+	status int
+	hash   string
+	length int64
+	count  int
+	// first URL seen with the response
+	sample string
+}
+
+// Groups the responses of the results written during the run by their fingerprint
+type clusters struct {
+	mu     sync.Mutex
+	groups map[string]*cluster
+}
+
+func newClusters() *clusters {
+	return &clusters{groups: map[string]*cluster{}}
+}
+
+// Adds the result to its cluster, errors have no response so are not clustered
+// responses without a body hash such as HEAD requests are grouped by content length
+func (c *clusters) add(r scanner.Result) {
+	if r.Result == scanner.ResultError {
+		return
+	}
+	key := fmt.Sprintf("%d %s", r.Status, r.BodyHash)
+	if len(r.BodyHash) == 0 {
+		key = fmt.Sprintf("%d length %d", r.Status, r.ContentLength)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	g, ok := c.groups[key]
+	if !ok {
+		g = &cluster{status: r.Status, hash: r.BodyHash, length: r.ContentLength, sample: r.URL}
+		c.groups[key] = g
+	}
+	g.count++
+}
+
+// Writes the clusters largest first with a sample URL from each
+func (c *clusters) print(w io.Writer) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	groups := make([]*cluster, 0, len(c.groups))
+	unique := 0
+	for _, g := range c.groups {
+		groups = append(groups, g)
+		if g.count == 1 {
+			unique++
+		}
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].count != groups[j].count {
+			return groups[i].count > groups[j].count
+		}
+		return groups[i].sample < groups[j].sample
+	})
+	fmt.Fprintf(w, "[*] Clusters: %d, %d with a unique response\n", len(groups), unique)
+	for _, g := range groups {
+		body := fmt.Sprintf("length %d", g.length)
+		if len(g.hash) > 12 {
+			body = "body " + g.hash[:12]
+		}
+		fmt.Fprintf(w, "    %6d  Status %d  %s  %s\n", g.count, g.status, body, g.sample)
+	}
+}
+
+// Adds each result to the clusters before writing
+type clusterWriter struct {
+	w        resultWriter
+	clusters *clusters
+}
+
+func (c *clusterWriter) Write(r scanner.Result) error {
+	c.clusters.add(r)
+	return c.w.Write(r)
+}
+
+func (c *clusterWriter) Close() error {
+	return c.w.Close()
+}
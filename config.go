@@ -0,0 +1,118 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/jessevdk/go-flags"
+	"gopkg.in/yaml.v3"
+)
+
+// Sets the options in the config file that were not supplied on the command line
+// so that command line options take precedence, must be called once the command line is parsed
+// files ending in .yaml or .yml are read as YAML and any others as INI
+func loadConfig(parser *flags.Parser, filename string) error {
+	raw, err := os.ReadFile(filename)
+	if err != nil {
+		return err
+	}
+	var values map[string][]string
+	switch strings.ToLower(filepath.Ext(filename)) {
+	case ".yaml", ".yml":
+		values, err = readYAMLConfig(raw)
+	default:
+		values, err = readINIConfig(raw)
+	}
+	if err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+
+	names := make([]string, 0, len(values))
+	for name := range values {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	// the values are set through the INI parser so each is converted the same as on the command line
+	var b strings.Builder
+	for _, name := range names {
+		opt := parser.FindOptionByLongName(name)
+		if opt == nil || name == "config" {
+			return fmt.Errorf("%s: unknown option '%s'", filename, name)
+		}
+		if opt.IsSet() && !opt.IsSetDefault() {
+			continue
+		}
+		_, isBool := opt.Value().(bool)
+		_, isBools := opt.Value().([]bool)
+		for _, v := range values[name] {
+			// false is the default for flags and would count as another -v for repeated flags
+			if (isBool || isBools) && v == "false" {
+				continue
+			}
+			fmt.Fprintf(&b, "%s = %s\n", name, strconv.Quote(v))
+		}
+	}
+	if err := flags.NewIniParser(parser).Parse(strings.NewReader(b.String())); err != nil {
+		return fmt.Errorf("%s: %v", filename, err)
+	}
+	return nil
+}
+
+// Reads a YAML mapping of the long option names to a value or list of values
+func readYAMLConfig(raw []byte) (map[string][]string, error) {
+	doc := map[string]interface{}{}
+	if err := yaml.Unmarshal(raw, &doc); err != nil {
+		return nil, err
+	}
+	values := map[string][]string{}
+	for name, value := range doc {
+		items, ok := value.([]interface{})
+		if !ok {
+			items = []interface{}{value}
+		}
+		for _, item := range items {
+			switch v := item.(type) {
+			case nil:
+			case string, bool, int, float64:
+				values[name] = append(values[name], fmt.Sprint(v))
+			default:
+				return nil, fmt.Errorf("option '%s' must be a value or list of values", name)
+			}
+		}
+	}
+	return values, nil
+}
+
+// Reads INI lines in the format 'name = value', an option can be repeated for each of its values
+// sections are ignored and lines starting with ; or # are comments
+func readINIConfig(raw []byte) (map[string][]string, error) {
+	values := map[string][]string{}
+	lines := bufio.NewScanner(bytes.NewReader(raw))
+	for n := 1; lines.Scan(); n++ {
+		line := strings.TrimSpace(lines.Text())
+		if len(line) == 0 || line[0] == ';' || line[0] == '#' || line[0] == '[' {
+			continue
+		}
+		name, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d must be in format 'name = value'", n)
+		}
+		name, value = strings.TrimSpace(name), strings.TrimSpace(value)
+		if len(value) > 0 && value[0] == '"' {
+			unquoted, err := strconv.Unquote(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d value is invalid: %v", n, err)
+			}
+			value = unquoted
+		}
+		values[name] = append(values[name], value)
+	}
+	return values, lines.Err()
+}
@@ -0,0 +1,58 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// prefix used by curl for HttpOnly cookies in a cookie file
+const httpOnlyPrefix = "#HttpOnly_"
+
+// Reads the cookies from a Netscape format cookie file, malformed lines are skipped
+func readCookieFile(filename string) ([]scanner.FileCookie, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var cookies []scanner.FileCookie
+	lines := bufio.NewScanner(f)
+	line := 0
+	for lines.Scan() {
+		line++
+		raw := strings.TrimSpace(lines.Text())
+		raw = strings.TrimPrefix(raw, httpOnlyPrefix)
+		if len(raw) == 0 || strings.HasPrefix(raw, "#") {
+			continue
+		}
+
+		fields := strings.Split(raw, "\t")
+		if len(fields) != 7 {
+			fmt.Fprintf(os.Stderr, "[!] %s:%d: skipping malformed cookie line\n", filename, line)
+			continue
+		}
+		expires, err := strconv.ParseInt(fields[4], 10, 64)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[!] %s:%d: skipping cookie with invalid expiry '%s'\n", filename, line, fields[4])
+			continue
+		}
+
+		cookies = append(cookies, scanner.FileCookie{
+			Domain:            fields[0],
+			IncludeSubdomains: strings.EqualFold(fields[1], "TRUE"),
+			Path:              fields[2],
+			Secure:            strings.EqualFold(fields[3], "TRUE"),
+			Expires:           expires,
+			Name:              fields[5],
+			Value:             fields[6],
+		})
+	}
+
+	return cookies, lines.Err()
+}
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sync"
+)
+
+// Prints each request instead of it being sent, is called from each of the threads
+type dryRun struct {
+	mu    sync.Mutex
+	w     io.Writer
+	count int
+}
+
+// Prints the request line, headers and any body of the request
+func (d *dryRun) print(req *http.Request) {
+	var body []byte
+	if req.GetBody != nil {
+		if r, err := req.GetBody(); err == nil {
+			body, _ = io.ReadAll(r)
+		}
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	d.count++
+	fmt.Fprintf(d.w, "%s %s\n", req.Method, req.URL)
+	// the header does not hold the host so an override such as --host is printed separately
+	if len(req.Host) > 0 && req.Host != req.URL.Host {
+		fmt.Fprintf(d.w, "Host: %s\r\n", req.Host)
+	}
+	req.Header.Write(d.w)
+	if len(body) > 0 {
+		fmt.Fprintf(d.w, "\n%s\n", body)
+	}
+	fmt.Fprintln(d.w)
+}
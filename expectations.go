@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Reads expectations from a file with a 'pattern profile result' rule on each line
+// such as '/admin/* guest denied', the first matching rule for a URL is used
+func readExpectations(filename string) ([]scanner.Expectation, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open expectations file: '%s'", filename)
+	}
+	defer f.Close()
+
+	var expectations []scanner.Expectation
+	lines := bufio.NewScanner(f)
+	line := 0
+	for lines.Scan() {
+		line++
+		raw := strings.TrimSpace(lines.Text())
+		if len(raw) == 0 || strings.HasPrefix(raw, "#") {
+			continue
+		}
+		fields := strings.Fields(raw)
+		if len(fields) != 3 {
+			return nil, fmt.Errorf("line %d is invalid, must be provided as 'pattern profile result'", line)
+		}
+		e, err := scanner.NewExpectation(fields[0], fields[1], strings.ToLower(fields[2]))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		expectations = append(expectations, e)
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("could not read expectations file: '%s'", filename)
+	}
+	return expectations, nil
+}
+
+// Reads the status each URL is expected to return from a file with a 'URL statuses' pair
+// on each line such as 'https://example.com/admin 401,403', the URLs are returned in order
+func readStatusExpectations(filename string) ([]string, map[string]scanner.StatusExpectation, error) {
+	f, err := openInput(filename)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not open expect file: '%s'", filename)
+	}
+	defer f.Close()
+
+	var urls []string
+	expectations := map[string]scanner.StatusExpectation{}
+	lines := bufio.NewScanner(f)
+	line := 0
+	for lines.Scan() {
+		line++
+		raw := strings.TrimSpace(lines.Text())
+		if len(raw) == 0 || strings.HasPrefix(raw, "#") {
+			continue
+		}
+		fields := strings.Fields(raw)
+		if len(fields) != 2 {
+			return nil, nil, fmt.Errorf("line %d is invalid, must be provided as 'URL statuses'", line)
+		}
+		if err := validURL(fields[0]); err != nil {
+			return nil, nil, fmt.Errorf("line %d: URL '%s' is invalid: %v", line, fields[0], err)
+		}
+		statuses, err := scanner.ParseStatuses(fields[1])
+		if err != nil {
+			return nil, nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		if _, ok := expectations[fields[0]]; !ok {
+			urls = append(urls, fields[0])
+		}
+		expectations[fields[0]] = scanner.StatusExpectation{Statuses: statuses, Raw: fields[1]}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, nil, fmt.Errorf("could not read expect file: '%s'", filename)
+	}
+	return urls, expectations, nil
+}
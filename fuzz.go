@@ -0,0 +1,234 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// keyword substituted in URLs when one is not supplied for a list
+const defaultFuzzKeyword = "FUZZ"
+
+// Values substituted for a keyword in URLs, either loaded from a wordlist
+// or generated from an inclusive numeric range
+type fuzzList struct {
+	keyword  string
+	words    []string
+	from, to int
+}
+
+// Number of values in the list
+func (l *fuzzList) len() int {
+	if l.words != nil {
+		return len(l.words)
+	}
+	return l.to - l.from + 1
+}
+
+// Value at index i in the list
+func (l *fuzzList) at(i int) string {
+	if l.words != nil {
+		return l.words[i]
+	}
+	return strconv.Itoa(l.from + i)
+}
+
+// Splits an optional keyword from a value supplied as KEYWORD=value
+func splitKeyword(raw string) (string, string) {
+	if keyword, value, ok := strings.Cut(raw, "="); ok && len(keyword) > 0 {
+		return keyword, value
+	}
+	return defaultFuzzKeyword, raw
+}
+
+// Reads a wordlist supplied as [KEYWORD=]filename, blank lines are skipped
+func readFuzzWordlist(raw string) (fuzzList, error) {
+	keyword, filename := splitKeyword(raw)
+	f, err := os.Open(filename)
+	if err != nil {
+		return fuzzList{}, fmt.Errorf("could not open fuzz wordlist: '%s'", filename)
+	}
+	defer f.Close()
+
+	list := fuzzList{keyword: keyword, words: []string{}}
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		if word := strings.TrimSpace(lines.Text()); len(word) > 0 {
+			list.words = append(list.words, word)
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return fuzzList{}, fmt.Errorf("could not read fuzz wordlist: '%s'", filename)
+	}
+	return list, nil
+}
+
+// Parses a numeric range supplied as [KEYWORD=]from-to
+func parseFuzzRange(raw string) (fuzzList, error) {
+	keyword, value := splitKeyword(raw)
+	rawFrom, rawTo, ok := strings.Cut(value, "-")
+	if !ok {
+		return fuzzList{}, fmt.Errorf("range '%s' is invalid, must be provided as [KEYWORD=]from-to", raw)
+	}
+	from, err := strconv.Atoi(rawFrom)
+	if err != nil {
+		return fuzzList{}, fmt.Errorf("range '%s' is invalid, must be provided as [KEYWORD=]from-to", raw)
+	}
+	to, err := strconv.Atoi(rawTo)
+	if err != nil || to < from {
+		return fuzzList{}, fmt.Errorf("range '%s' is invalid, from must not be greater than to", raw)
+	}
+	return fuzzList{keyword: keyword, from: from, to: to}, nil
+}
+
+// Expands each target URL containing keywords from the lists into a target for every
+// combination of values, targets without any keywords are passed on unchanged
+// stops expanding once ctx is done
+func expandURLs(ctx context.Context, in <-chan scanner.Target, lists []fuzzList) <-chan scanner.Target {
+	out := make(chan scanner.Target)
+
+	go func() {
+		defer close(out)
+
+		for t := range in {
+			var used []*fuzzList
+			for i := range lists {
+				if strings.Contains(t.URL, lists[i].keyword) {
+					used = append(used, &lists[i])
+				}
+			}
+			if !expand(ctx, t, used, out) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Recursively substitutes the values of each list into the target URL sending
+// the results on out, returns false once ctx is done
+func expand(ctx context.Context, t scanner.Target, lists []*fuzzList, out chan<- scanner.Target) bool {
+	if len(lists) == 0 {
+		select {
+		case out <- t:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	list := lists[0]
+	for i := 0; i < list.len(); i++ {
+		expanded := t
+		expanded.URL = strings.ReplaceAll(t.URL, list.keyword, list.at(i))
+		if !expand(ctx, expanded, lists[1:], out) {
+			return false
+		}
+	}
+	return true
+}
+
+// Expands each target URL containing {a,b} sets or {from..to} ranges into a target for every
+// combination, braces without a set or range such as {id} are left unchanged
+// stops expanding once ctx is done
+func expandBraceURLs(ctx context.Context, in <-chan scanner.Target) <-chan scanner.Target {
+	out := make(chan scanner.Target)
+
+	go func() {
+		defer close(out)
+
+		for t := range in {
+			ok := expandBraces(t.URL, func(raw string) bool {
+				expanded := t
+				expanded.URL = raw
+				select {
+				case out <- expanded:
+					return true
+				case <-ctx.Done():
+					return false
+				}
+			})
+			if !ok {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Recursively expands the first brace set or range in raw calling emit with each URL
+// returns false once emit returns false
+func expandBraces(raw string, emit func(string) bool) bool {
+	for start := 0; start < len(raw); {
+		open := strings.IndexByte(raw[start:], '{')
+		if open < 0 {
+			break
+		}
+		open += start
+		end := strings.IndexByte(raw[open:], '}')
+		if end < 0 {
+			break
+		}
+		end += open
+
+		values := braceValues(raw[open+1 : end])
+		if values == nil {
+			start = end + 1
+			continue
+		}
+		for _, v := range values {
+			// expand the remaining braces after the value substituted
+			prefix := raw[:open] + v
+			ok := expandBraces(raw[end+1:], func(rest string) bool {
+				return emit(prefix + rest)
+			})
+			if !ok {
+				return false
+			}
+		}
+		return true
+	}
+	return emit(raw)
+}
+
+// Returns the values of a brace set such as 'users,admins' or range such as '1..50'
+// a range keeps the width of zero padded values such as '01..10', nil is returned for anything else
+func braceValues(inner string) []string {
+	if rawFrom, rawTo, ok := strings.Cut(inner, ".."); ok {
+		from, err := strconv.Atoi(rawFrom)
+		if err != nil {
+			return nil
+		}
+		to, err := strconv.Atoi(rawTo)
+		if err != nil {
+			return nil
+		}
+		width := 0
+		if len(rawFrom) > 1 && rawFrom[0] == '0' {
+			width = len(rawFrom)
+		}
+		step := 1
+		if to < from {
+			step = -1
+		}
+		var values []string
+		for i := from; ; i += step {
+			values = append(values, fmt.Sprintf("%0*d", width, i))
+			if i == to {
+				break
+			}
+		}
+		return values
+	}
+	if strings.Contains(inner, ",") {
+		return strings.Split(inner, ",")
+	}
+	return nil
+}
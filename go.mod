@@ -3,6 +3,10 @@ module github.com/stavinski/gowac
 go 1.18
 
 require (
+	github.com/andybalholm/brotli v1.1.1
 	github.com/jessevdk/go-flags v1.5.0
-	golang.org/x/sys v0.0.0-20210320140829-1e4c9ba3b0c4 // indirect
+	golang.org/x/time v0.5.0
+	gopkg.in/yaml.v3 v3.0.1
 )
+
+require golang.org/x/sys v0.0.0-20210320140829-1e4c9ba3b0c4 // indirect
@@ -0,0 +1,299 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"fmt"
+	"hash/fnv"
+	"io"
+	"math/rand"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/stavinski/gowac/scanner"
+	"github.com/stavinski/gowac/utils"
+)
+
+// Read targets from each of the supplied filenames in turn and return on a chan after any inline URLs
+// when base is supplied each URL read from a file is joined to it as a path, when csv is set the
+// files are read as CSV rows, files that cannot be opened are skipped, stops reading once ctx is done
+func readURLs(ctx context.Context, filenames []string, inline []string, base string, csv bool) <-chan scanner.Target {
+	out := make(chan scanner.Target)
+
+	go func() {
+		defer close(out)
+
+		for _, raw := range inline {
+			select {
+			case out <- scanner.Target{URL: raw}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		for _, filename := range filenames {
+			read := readURLFile
+			if csv {
+				read = readCSVFile
+			}
+			if !read(ctx, filename, base, out) {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// UTF-8 byte order mark written at the start of files by some Windows tools
+const utf8BOM = "\xef\xbb\xbf"
+
+// Opens the file for reading, stdin is used when - is provided
+// a leading UTF-8 BOM is skipped so that it is not part of the first URL
+func openInput(filename string) (io.ReadCloser, error) {
+	var f io.ReadCloser = io.NopCloser(os.Stdin)
+	if filename != "-" {
+		var err error
+		if f, err = os.Open(filename); err != nil {
+			return nil, err
+		}
+	}
+	r := bufio.NewReader(f)
+	if b, err := r.Peek(len(utf8BOM)); err == nil && string(b) == utf8BOM {
+		r.Discard(len(utf8BOM))
+	}
+	return struct {
+		io.Reader
+		io.Closer
+	}{r, f}, nil
+}
+
+// Reads the URLs from a single file onto the chan returning false once ctx is done
+// each line is trimmed including any CRLF ending and blank lines or comments starting with # are skipped
+func readURLFile(ctx context.Context, filename, base string, out chan<- scanner.Target) bool {
+	f, err := openInput(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] could not open file: '%s'\n", filename)
+		return true
+	}
+	defer f.Close()
+
+	lines := bufio.NewScanner(f)
+	for lines.Scan() {
+		raw := strings.TrimSpace(lines.Text())
+		// skip blank lines and comments
+		if len(raw) == 0 || strings.HasPrefix(raw, "#") {
+			continue
+		}
+		if len(base) > 0 {
+			raw = joinURL(base, raw)
+		}
+		select {
+		case out <- scanner.Target{URL: raw}:
+		case <-ctx.Done():
+			return false
+		}
+	}
+	return true
+}
+
+// Only forwards the targets with a valid http or https URL, the others are reported
+// invalid is called with each target skipped and may be nil
+func validURLs(ctx context.Context, in <-chan scanner.Target, invalid func(scanner.Target)) <-chan scanner.Target {
+	return utils.Filter(ctx, in, func(t scanner.Target) bool {
+		if err := validURL(t.URL); err != nil {
+			fmt.Fprintf(os.Stderr, "[!] skipping URL '%s': %v\n", t.URL, err)
+			if invalid != nil {
+				invalid(t)
+			}
+			return false
+		}
+		return true
+	})
+}
+
+// Checks the URL can be requested
+func validURL(raw string) error {
+	u, err := url.ParseRequestURI(raw)
+	if err != nil {
+		return fmt.Errorf("invalid URL")
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return fmt.Errorf("scheme must be http or https")
+	}
+	if len(u.Host) == 0 {
+		return fmt.Errorf("missing host")
+	}
+	return nil
+}
+
+// Reads targets from a single CSV file onto the chan returning false once ctx is done
+// each row is url,method,headers,body where headers are separated by new lines and
+// all but the url are optional, invalid rows are reported and skipped
+func readCSVFile(ctx context.Context, filename, base string, out chan<- scanner.Target) bool {
+	f, err := openInput(filename)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "[!] could not open file: '%s'\n", filename)
+		return true
+	}
+	defer f.Close()
+
+	rows := csv.NewReader(f)
+	rows.FieldsPerRecord = -1
+	line := 0
+	for {
+		row, err := rows.Read()
+		if err == io.EOF {
+			return true
+		}
+		line++
+		if err != nil {
+			// the reader cannot continue after some errors so stop reading the file
+			fmt.Fprintf(os.Stderr, "[!] could not read CSV file '%s': %v\n", filename, err)
+			return true
+		}
+		// skip an optional header row
+		if line == 1 && strings.EqualFold(strings.TrimSpace(row[0]), "url") {
+			continue
+		}
+		t, err := parseCSVRow(row, base)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[!] skipping row %d in '%s': %v\n", line, filename, err)
+			continue
+		}
+		select {
+		case out <- t:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// Parses a CSV row into a target
+func parseCSVRow(row []string, base string) (scanner.Target, error) {
+	t := scanner.Target{URL: strings.TrimSpace(row[0])}
+	if len(base) > 0 {
+		t.URL = joinURL(base, t.URL)
+	}
+	if _, err := url.ParseRequestURI(t.URL); err != nil {
+		return t, fmt.Errorf("url '%s' is invalid", t.URL)
+	}
+
+	if len(row) > 1 && len(strings.TrimSpace(row[1])) > 0 {
+		t.Method = strings.ToUpper(strings.TrimSpace(row[1]))
+		if !validMethod(t.Method) {
+			return t, fmt.Errorf("method '%s' is invalid", t.Method)
+		}
+	}
+
+	if len(row) > 2 && len(strings.TrimSpace(row[2])) > 0 {
+		t.Header = http.Header{}
+		for _, h := range strings.Split(row[2], "\n") {
+			if len(strings.TrimSpace(h)) == 0 {
+				continue
+			}
+			name, value, err := scanner.ParseHeader(h)
+			if err != nil {
+				return t, err
+			}
+			t.Header.Add(name, value)
+		}
+	}
+
+	if len(row) > 3 && len(row[3]) > 0 {
+		t.Body = []byte(row[3])
+	}
+	return t, nil
+}
+
+// Reads all the targets from the chan and returns them on a chan in a random order
+// determined by seed, stops once ctx is done
+func shuffleURLs(ctx context.Context, in <-chan scanner.Target, seed int64) <-chan scanner.Target {
+	out := make(chan scanner.Target)
+
+	go func() {
+		defer close(out)
+
+		var targets []scanner.Target
+		for t := range in {
+			targets = append(targets, t)
+		}
+		r := rand.New(rand.NewSource(seed))
+		r.Shuffle(len(targets), func(i, j int) { targets[i], targets[j] = targets[j], targets[i] })
+		for _, t := range targets {
+			select {
+			case out <- t:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Prepends the scheme to each target URL without one such as 'example.com/admin'
+// each URL changed is logged when logger is not nil
+func addScheme(ctx context.Context, in <-chan scanner.Target, scheme string, logger scanner.Logger) <-chan scanner.Target {
+	return utils.Map(ctx, in, func(t scanner.Target) scanner.Target {
+		if !strings.Contains(t.URL, "://") {
+			raw := t.URL
+			t.URL = scheme + "://" + strings.TrimLeft(raw, "/")
+			if logger != nil {
+				logger.Log(scanner.LevelInfo, "added scheme", "url", raw, "normalized", t.URL)
+			}
+		}
+		return t
+	})
+}
+
+// Joins the path to the base URL so that there is a single slash between them
+func joinURL(base, path string) string {
+	return strings.TrimRight(base, "/") + "/" + strings.TrimLeft(path, "/")
+}
+
+// Passes on the first n targets from the chan then calls stop so the earlier
+// stages stop reading, out is closed once n targets are passed on, in is closed or ctx is done
+func limitURLs(ctx context.Context, in <-chan scanner.Target, n int, stop context.CancelFunc) <-chan scanner.Target {
+	out := make(chan scanner.Target)
+
+	go func() {
+		defer close(out)
+		defer stop()
+
+		sent := 0
+		for t := range in {
+			select {
+			case out <- t:
+			case <-ctx.Done():
+				return
+			}
+			sent++
+			if sent == n {
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// Key used to identify duplicate targets
+func targetKey(t scanner.Target) string {
+	return t.Method + " " + t.URL
+}
+
+// Hashes the target URL once normalized so that equivalent URLs have the same hash
+func hashURL(t scanner.Target) uint64 {
+	raw := t.URL
+	if u, err := url.Parse(raw); err == nil {
+		u.Scheme = strings.ToLower(u.Scheme)
+		u.Host = strings.ToLower(u.Host)
+		raw = u.String()
+	}
+	h := fnv.New64a()
+	h.Write([]byte(t.Method + " " + raw))
+	return h.Sum64()
+}
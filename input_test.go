@@ -0,0 +1,26 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestReadURLsBOMAndCRLF(t *testing.T) {
+	filename := filepath.Join(t.TempDir(), "urls.txt")
+	content := utf8BOM + "https://example.com/admin\r\nhttps://example.com/users\r\n\r\n# comment\r\nhttps://example.com/logs\r\n"
+	if err := os.WriteFile(filename, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var got []string
+	for target := range readURLs(context.Background(), []string{filename}, nil, "", false) {
+		got = append(got, target.URL)
+	}
+	want := []string{"https://example.com/admin", "https://example.com/users", "https://example.com/logs"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %q got %q", want, got)
+	}
+}
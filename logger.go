@@ -0,0 +1,49 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Names of the log levels written
+var levelNames = map[scanner.LogLevel]string{
+	scanner.LevelInfo:  "info",
+	scanner.LevelDebug: "debug",
+}
+
+// Writes events at or below the level as key=value lines so that they can be
+// separated from the results
+type kvLogger struct {
+	mu    sync.Mutex
+	w     io.Writer
+	level scanner.LogLevel
+}
+
+func (l *kvLogger) Log(level scanner.LogLevel, msg string, kv ...interface{}) {
+	if level > l.level {
+		return
+	}
+	var b strings.Builder
+	fmt.Fprintf(&b, "time=%s level=%s msg=%s", time.Now().UTC().Format(time.RFC3339Nano), levelNames[level], logValue(msg))
+	for i := 0; i+1 < len(kv); i += 2 {
+		fmt.Fprintf(&b, " %v=%s", kv[i], logValue(kv[i+1]))
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	fmt.Fprintln(l.w, b.String())
+}
+
+// Formats the value quoting it when it contains spaces or quotes
+func logValue(v interface{}) string {
+	s := fmt.Sprint(v)
+	if strings.ContainsAny(s, " \"=\t\n") || len(s) == 0 {
+		return fmt.Sprintf("%q", s)
+	}
+	return s
+}
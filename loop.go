@@ -0,0 +1,106 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Flags the results that changed since the previous cycle of a loop, every result is
+// written on so that stats and the other writers see them, see filter for the output
+type loopWriter struct {
+	mu       sync.Mutex
+	w        resultWriter
+	all      bool
+	previous map[string]snapshotEntry
+	current  map[string]snapshotEntry
+	// keys of the results that are new or changed in the current cycle
+	changed map[string]struct{}
+}
+
+// Creates the loop writer, w must be set to the writer to write on to before writing
+func newLoopWriter(all bool) *loopWriter {
+	return &loopWriter{all: all, current: map[string]snapshotEntry{}, changed: map[string]struct{}{}}
+}
+
+func (l *loopWriter) Write(r scanner.Result) error {
+	cur := newSnapshotEntry(r)
+	l.mu.Lock()
+	l.current[cur.key()] = cur
+	first := l.previous == nil
+	prev, ok := l.previous[cur.key()]
+	if !first {
+		if !ok {
+			r.Unexpected = true
+			r.Message = "NEW since the previous cycle: " + r.Message
+			l.changed[cur.key()] = struct{}{}
+		} else if changes := snapshotChanges(prev, cur); len(changes) > 0 {
+			r.Unexpected = true
+			r.Message = fmt.Sprintf("CHANGED since the previous cycle (%s): %s", strings.Join(changes, ", "), r.Message)
+			l.changed[cur.key()] = struct{}{}
+		}
+	}
+	l.mu.Unlock()
+	return l.w.Write(r)
+}
+
+// Checks if the result is output, every result is output in the first cycle or when all is set
+func (l *loopWriter) shows(r scanner.Result) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.previous == nil || l.all {
+		return true
+	}
+	e := newSnapshotEntry(r)
+	_, ok := l.changed[e.key()]
+	return ok
+}
+
+// Returns a writer that only writes on the results that the loop shows
+func (l *loopWriter) filter(w resultWriter) resultWriter {
+	return &loopFilterWriter{w: w, loop: l}
+}
+
+// Only writes the results that changed since the previous cycle of a loop
+type loopFilterWriter struct {
+	w    resultWriter
+	loop *loopWriter
+}
+
+func (f *loopFilterWriter) Write(r scanner.Result) error {
+	if !f.loop.shows(r) {
+		return nil
+	}
+	return f.w.Write(r)
+}
+
+func (f *loopFilterWriter) Close() error {
+	return f.w.Close()
+}
+
+// Starts the next cycle reporting any URLs from the previous cycle that were not checked in this one
+func (l *loopWriter) next() {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var removed []string
+	for key, e := range l.previous {
+		if _, ok := l.current[key]; !ok {
+			removed = append(removed, fmt.Sprintf("%s %s", e.Method, e.URL))
+		}
+	}
+	sort.Strings(removed)
+	for _, r := range removed {
+		fmt.Fprintf(os.Stderr, "[!] not checked since the previous cycle: %s\n", r)
+	}
+	l.previous = l.current
+	l.current = map[string]snapshotEntry{}
+	l.changed = map[string]struct{}{}
+}
+
+func (l *loopWriter) Close() error {
+	return l.w.Close()
+}
@@ -0,0 +1,68 @@
+package main
+
+import (
+	"testing"
+
+	"github.com/jessevdk/go-flags"
+)
+
+// Parses the args into options with the defaults set
+func parseOptions(t *testing.T, args ...string) *Options {
+	t.Helper()
+	opts := &Options{}
+	if _, err := flags.NewParser(opts, flags.HelpFlag).ParseArgs(args); err != nil {
+		t.Fatalf("could not parse args %v: %v", args, err)
+	}
+	return opts
+}
+
+func TestValidateChecks(t *testing.T) {
+	tests := []struct {
+		name  string
+		args  []string
+		valid bool
+	}{
+		{"status only", []string{"-s", "403"}, true},
+		{"body only", []string{"-b", "denied"}, true},
+		{"redirect only", []string{"-r", "/login"}, true},
+		{"status body and redirect", []string{"-s", "401,403", "-b", "denied", "-r", "/login"}, true},
+		{"no checks", nil, false},
+		{"status out of range", []string{"-s", "99"}, false},
+		{"status out of range with body", []string{"-s", "1000", "-b", "denied"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := parseOptions(t, append(tt.args, "https://example.com/admin")...)
+			err := opts.Validate()
+			if tt.valid && err != nil {
+				t.Errorf("expected options to be valid: %v", err)
+			}
+			if !tt.valid && err == nil {
+				t.Error("expected options to be invalid")
+			}
+		})
+	}
+}
+
+func TestIsSchemelessURL(t *testing.T) {
+	tests := []struct {
+		arg  string
+		want bool
+	}{
+		{"example.com/admin", true},
+		{"example.com", true},
+		{"127.0.0.1:8080/admin", true},
+		{"main.go", false},
+		{"targets.txt", false},
+		{"targets.txt/admin", true},
+		{"example.com:8443", true},
+		{"urls", false},
+		{"-", false},
+		{"https://example.com/admin", false},
+	}
+	for _, tt := range tests {
+		if got := isSchemelessURL(tt.arg); got != tt.want {
+			t.Errorf("isSchemelessURL(%q) expected %v got %v", tt.arg, tt.want, got)
+		}
+	}
+}
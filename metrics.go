@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Upper bounds in seconds of the request latency histogram buckets
+var latencyBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Metrics for the results written during the run exposed in the Prometheus text format
+type metrics struct {
+	mu      sync.Mutex
+	results map[string]uint64
+	// cumulative count of latencies in each bucket
+	buckets []uint64
+	sum     float64
+	count   uint64
+}
+
+func newMetrics() *metrics {
+	return &metrics{
+		results: map[string]uint64{},
+		buckets: make([]uint64, len(latencyBuckets)),
+	}
+}
+
+// Adds the result to the metrics
+func (m *metrics) add(r scanner.Result) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.results[r.Result]++
+	latency := float64(r.LatencyMS) / 1000
+	for i, le := range latencyBuckets {
+		if latency <= le {
+			m.buckets[i]++
+		}
+	}
+	m.sum += latency
+	m.count++
+}
+
+func (m *metrics) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+
+	fmt.Fprintln(w, "# HELP gowac_requests_total Number of requests sent including retries.")
+	fmt.Fprintln(w, "# TYPE gowac_requests_total counter")
+	fmt.Fprintf(w, "gowac_requests_total %d\n", scanner.Requests())
+
+	fmt.Fprintln(w, "# HELP gowac_in_flight_requests Number of requests currently being made.")
+	fmt.Fprintln(w, "# TYPE gowac_in_flight_requests gauge")
+	fmt.Fprintf(w, "gowac_in_flight_requests %d\n", scanner.InFlight())
+
+	fmt.Fprintln(w, "# HELP gowac_results_total Number of URLs checked by result.")
+	fmt.Fprintln(w, "# TYPE gowac_results_total counter")
+	for _, result := range []string{scanner.ResultGranted, scanner.ResultDenied, scanner.ResultError} {
+		fmt.Fprintf(w, "gowac_results_total{result=%q} %d\n", result, m.results[result])
+	}
+
+	fmt.Fprintln(w, "# HELP gowac_request_duration_seconds Latency of the final request made for each URL.")
+	fmt.Fprintln(w, "# TYPE gowac_request_duration_seconds histogram")
+	for i, le := range latencyBuckets {
+		fmt.Fprintf(w, "gowac_request_duration_seconds_bucket{le=\"%g\"} %d\n", le, m.buckets[i])
+	}
+	fmt.Fprintf(w, "gowac_request_duration_seconds_bucket{le=\"+Inf\"} %d\n", m.count)
+	fmt.Fprintf(w, "gowac_request_duration_seconds_sum %g\n", m.sum)
+	fmt.Fprintf(w, "gowac_request_duration_seconds_count %d\n", m.count)
+}
+
+// Serves the metrics on addr until the returned func is called to shut the server down
+func serveMetrics(addr string, m *metrics) func() {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", m)
+	srv := &http.Server{Addr: addr, Handler: mux}
+	go func() {
+		if err := srv.ListenAndServe(); err != nil && err != http.ErrServerClosed {
+			fmt.Fprintf(os.Stderr, "[!] could not serve metrics: %v\n", err)
+		}
+	}()
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		srv.Shutdown(ctx)
+	}
+}
+
+// Adds each result to the metrics before writing
+type metricsWriter struct {
+	w       resultWriter
+	metrics *metrics
+}
+
+func (m *metricsWriter) Write(r scanner.Result) error {
+	m.metrics.add(r)
+	return m.w.Write(r)
+}
+
+func (m *metricsWriter) Close() error {
+	return m.w.Close()
+}
@@ -0,0 +1,313 @@
+package main
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Writes results in a specific format, must be safe to call concurrently
+type resultWriter interface {
+	Write(r scanner.Result) error
+	// Close writes any buffered results
+	Close() error
+}
+
+// Creates the result writer for the format, color is only used for text
+func newResultWriter(format string, w io.Writer, color bool) resultWriter {
+	switch format {
+	case "json":
+		return &jsonWriter{w: w}
+	case "ndjson":
+		return &ndjsonWriter{enc: json.NewEncoder(w)}
+	case "csv":
+		return &csvWriter{w: csv.NewWriter(w)}
+	default:
+		return &textWriter{w: w, color: color}
+	}
+}
+
+// Writes results as human readable lines
+type textWriter struct {
+	mu    sync.Mutex
+	w     io.Writer
+	color bool
+}
+
+// Prefixes used for each result
+var textPrefixes = map[string]string{
+	scanner.ResultGranted: "[+]",
+	scanner.ResultDenied:  "[-]",
+	scanner.ResultError:   "[!]",
+}
+
+// Prefix used for results that differ from the expected result
+const unexpectedPrefix = "[!!]"
+
+// ANSI colors used for each result
+var textColors = map[string]string{
+	scanner.ResultGranted: "\033[32m",
+	scanner.ResultDenied:  "\033[31m",
+	scanner.ResultError:   "\033[33m",
+}
+
+// ANSI code to reset the color
+const colorReset = "\033[0m"
+
+// Checks if the color setting means color should be used when writing to f
+// auto only uses color when f is a terminal
+func useColor(setting string, f *os.File) bool {
+	switch setting {
+	case "always":
+		return true
+	case "never":
+		return false
+	}
+	fi, err := f.Stat()
+	if err != nil {
+		return false
+	}
+	return (fi.Mode() & os.ModeCharDevice) != 0
+}
+
+func (t *textWriter) Write(r scanner.Result) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	suffix := fmt.Sprintf(" (%dms)", r.LatencyMS)
+	if len(r.Title) > 0 {
+		suffix = fmt.Sprintf(" [%s]", r.Title) + suffix
+	}
+	if r.Attempts > 1 {
+		suffix += fmt.Sprintf(" after %d attempts", r.Attempts)
+	}
+	if r.Truncated {
+		suffix += " (body truncated)"
+	}
+	profile := ""
+	if len(r.Profile) > 0 {
+		profile = " as " + r.Profile
+	}
+	prefix := textPrefixes[r.Result]
+	if r.Unexpected {
+		prefix = unexpectedPrefix
+	}
+	line := fmt.Sprintf("%s %s <%s>%s: %s%s", prefix, r.Method, r.URL, profile, r.Message, suffix)
+	if len(r.Timestamp) > 0 {
+		line = r.Timestamp + " " + line
+	}
+	if t.color {
+		line = textColors[r.Result] + line + colorReset
+	}
+	_, err := fmt.Fprintln(t.w, line)
+	return err
+}
+
+func (t *textWriter) Close() error {
+	return nil
+}
+
+// Writes results as a JSON array of objects per URL once closed
+type jsonWriter struct {
+	mu      sync.Mutex
+	w       io.Writer
+	results []scanner.Result
+}
+
+func (j *jsonWriter) Write(r scanner.Result) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.results = append(j.results, r)
+	return nil
+}
+
+func (j *jsonWriter) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.results == nil {
+		j.results = []scanner.Result{}
+	}
+	enc := json.NewEncoder(j.w)
+	enc.SetIndent("", "  ")
+	return enc.Encode(j.results)
+}
+
+// Writes results as a timestamped JSON object per line as soon as each is written
+type ndjsonWriter struct {
+	mu  sync.Mutex
+	enc *json.Encoder
+}
+
+func (n *ndjsonWriter) Write(r scanner.Result) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+	if len(r.Timestamp) == 0 {
+		r.Timestamp = time.Now().Format(time.RFC3339Nano)
+	}
+	return n.enc.Encode(r)
+}
+
+func (n *ndjsonWriter) Close() error {
+	return nil
+}
+
+// Header row written for CSV output
+var csvHeader = []string{"url", "method", "status", "result", "rule", "content_length", "elapsed_ms", "profile"}
+
+// Writes results as CSV rows with a header row
+type csvWriter struct {
+	mu     sync.Mutex
+	w      *csv.Writer
+	header bool
+}
+
+func (c *csvWriter) Write(r scanner.Result) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if !c.header {
+		if err := c.w.Write(csvHeader); err != nil {
+			return err
+		}
+		c.header = true
+	}
+	err := c.w.Write([]string{
+		r.URL,
+		r.Method,
+		strconv.Itoa(r.Status),
+		r.Result,
+		r.Rule,
+		strconv.FormatInt(r.ContentLength, 10),
+		strconv.FormatInt(r.LatencyMS, 10),
+		r.Profile,
+	})
+	if err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvWriter) Close() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+// Sets the timestamp of each result to when it was written
+type timestampWriter struct {
+	w resultWriter
+}
+
+func (t *timestampWriter) Write(r scanner.Result) error {
+	r.Timestamp = time.Now().Format(time.RFC3339)
+	return t.w.Write(r)
+}
+
+func (t *timestampWriter) Close() error {
+	return t.w.Close()
+}
+
+// Only writes results that are one of the results
+type filterWriter struct {
+	w       resultWriter
+	results []string
+}
+
+func (f *filterWriter) Write(r scanner.Result) error {
+	for _, result := range f.results {
+		if r.Result == result {
+			return f.w.Write(r)
+		}
+	}
+	return nil
+}
+
+func (f *filterWriter) Close() error {
+	return f.w.Close()
+}
+
+// Aborts the run once there have been max consecutive error results
+type errorLimitWriter struct {
+	w           resultWriter
+	max         int
+	consecutive int
+	abort       func()
+	aborted     bool
+}
+
+func (e *errorLimitWriter) Write(r scanner.Result) error {
+	if r.Result != scanner.ResultError {
+		e.consecutive = 0
+		return e.w.Write(r)
+	}
+	e.consecutive++
+	if e.consecutive >= e.max && !e.aborted {
+		e.aborted = true
+		fmt.Fprintf(os.Stderr, "[!] aborting: too many errors, %d requests in a row failed\n", e.consecutive)
+		e.abort()
+	}
+	return e.w.Write(r)
+}
+
+func (e *errorLimitWriter) Close() error {
+	return e.w.Close()
+}
+
+// Writes the URL of each error result to a file so that it can be checked again
+// each URL is only written once when it errors for more than one profile
+type errorsWriter struct {
+	mu      sync.Mutex
+	w       resultWriter
+	f       *os.File
+	written map[string]struct{}
+}
+
+func (e *errorsWriter) Write(r scanner.Result) error {
+	if r.Result == scanner.ResultError {
+		e.mu.Lock()
+		_, seen := e.written[r.URL]
+		var err error
+		if !seen {
+			e.written[r.URL] = struct{}{}
+			_, err = fmt.Fprintln(e.f, r.URL)
+		}
+		e.mu.Unlock()
+		if err != nil {
+			return err
+		}
+	}
+	return e.w.Write(r)
+}
+
+func (e *errorsWriter) Close() error {
+	if err := e.w.Close(); err != nil {
+		return err
+	}
+	return e.f.Close()
+}
+
+// Writes results to each of the writers
+type multiWriter []resultWriter
+
+func (m multiWriter) Write(r scanner.Result) error {
+	for _, w := range m {
+		if err := w.Write(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m multiWriter) Close() error {
+	for _, w := range m {
+		if err := w.Close(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bufio"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Reads auth profiles from an INI style file, each [name] section starts a profile
+// with cookie, bearer, auth and repeatable header keys, values can use env:NAME
+// a section with no keys is an anonymous profile
+func readProfiles(filename string) ([]scanner.Profile, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open profiles file: '%s'", filename)
+	}
+	defer f.Close()
+
+	var profiles []scanner.Profile
+	names := map[string]bool{}
+	lines := bufio.NewScanner(f)
+	line := 0
+	for lines.Scan() {
+		line++
+		raw := strings.TrimSpace(lines.Text())
+		if len(raw) == 0 || strings.HasPrefix(raw, "#") || strings.HasPrefix(raw, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(raw, "[") && strings.HasSuffix(raw, "]") {
+			name := strings.TrimSpace(raw[1 : len(raw)-1])
+			if len(name) == 0 || names[name] {
+				return nil, fmt.Errorf("profile name '%s' on line %d is empty or already used", name, line)
+			}
+			names[name] = true
+			profiles = append(profiles, scanner.Profile{Name: name, Headers: http.Header{}})
+			continue
+		}
+		if len(profiles) == 0 {
+			return nil, fmt.Errorf("line %d is not in a [profile] section", line)
+		}
+
+		key, value, ok := strings.Cut(raw, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d is invalid, must be provided as 'key = value'", line)
+		}
+		value, err := resolveSecret(strings.TrimSpace(value))
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %v", line, err)
+		}
+		p := &profiles[len(profiles)-1]
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "cookie":
+			p.Cookie = value
+		case "bearer":
+			p.Bearer = value
+		case "auth":
+			username, pass, ok := strings.Cut(value, ":")
+			if !ok {
+				return nil, fmt.Errorf("line %d: auth value is invalid, must be provided as 'username:password'", line)
+			}
+			p.Username, p.Password = username, pass
+		case "header":
+			name, hv, err := scanner.ParseHeader(value)
+			if err != nil {
+				return nil, fmt.Errorf("line %d: %v", line, err)
+			}
+			p.Headers.Add(name, hv)
+		default:
+			return nil, fmt.Errorf("line %d: unknown key '%s'", line, strings.TrimSpace(key))
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return nil, fmt.Errorf("could not read profiles file: '%s'", filename)
+	}
+	if len(profiles) == 0 {
+		return nil, fmt.Errorf("profiles file '%s' contains no profiles", filename)
+	}
+	return profiles, nil
+}
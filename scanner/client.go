@@ -0,0 +1,70 @@
+package scanner
+
+import (
+	"crypto/tls"
+	"errors"
+	"net"
+	"net/http"
+	"time"
+)
+
+// ErrTooManyRedirects is returned when following redirects goes beyond the max redirects
+var ErrTooManyRedirects = errors.New("too many redirects")
+
+// ErrDryRun is returned instead of a response when the config has DryRun set
+var ErrDryRun = errors.New("dry run")
+
+// NewClient builds the client shared by all the request threads based on the config
+func NewClient(cfg Config) *http.Client {
+	dialer := &net.Dialer{
+		Timeout:   30 * time.Second,
+		KeepAlive: 30 * time.Second,
+	}
+	if cfg.ConnectTimeout > 0 {
+		dialer.Timeout = cfg.ConnectTimeout
+	}
+	if cfg.SourceIP != nil {
+		dialer.LocalAddr = &net.TCPAddr{IP: cfg.SourceIP}
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.DialContext = dialer.DialContext
+	transport.TLSClientConfig = &tls.Config{
+		RootCAs:            cfg.RootCAs,
+		Certificates:       cfg.Certificates,
+		InsecureSkipVerify: cfg.Insecure,
+	}
+
+	// threads mostly share a small number of hosts so allow all idle conns to be
+	// kept for a single host rather than the default of 2
+	if cfg.MaxIdleConns > 0 {
+		transport.MaxIdleConns = cfg.MaxIdleConns
+		transport.MaxIdleConnsPerHost = cfg.MaxIdleConns
+	}
+	transport.MaxConnsPerHost = cfg.MaxConnsPerHost
+	transport.IdleConnTimeout = 90 * time.Second
+
+	if cfg.HTTP1 {
+		transport.ForceAttemptHTTP2 = false
+		transport.TLSNextProto = map[string]func(string, *tls.Conn) http.RoundTripper{}
+	}
+
+	// credentials embedded in the proxy URL are used for proxy auth
+	if cfg.Proxy != nil {
+		transport.Proxy = http.ProxyURL(cfg.Proxy)
+	}
+
+	return &http.Client{
+		Transport: transport,
+		CheckRedirect: func(req *http.Request, via []*http.Request) error {
+			// do not perform redirects unless following
+			if !cfg.Follow {
+				return http.ErrUseLastResponse
+			}
+			if len(via) > cfg.MaxRedirects {
+				return ErrTooManyRedirects
+			}
+			return nil
+		},
+	}
+}
@@ -0,0 +1,140 @@
+package scanner
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// Config used to make the requests and check the responses
+type Config struct {
+	// request options
+
+	// Client used to make requests, when nil a client is built with NewClient
+	Client *http.Client
+	// Logger receives diagnostic events about the requests made, may be nil
+	Logger Logger
+	// Threads is the number of concurrent requests, defaults to 1
+	Threads int
+	// Buffer is the size of the chans between each stage of the pipeline, defaults to Threads
+	// this bounds the number of responses held in memory when checking is slower than requesting
+	Buffer int
+	// Ordered reports the results in the order of the targets, responses that complete early
+	// are held in memory until all those before them so a slow response can hold many
+	Ordered bool
+	// Method is the HTTP method to use, defaults to GET
+	Method  string
+	Headers http.Header
+	Cookie  string
+	// Cookies are only sent when they match the request
+	Cookies            []FileCookie
+	Username, Password string
+	Bearer             string
+	UserAgent          string
+	// Host is sent as the host of each request instead of the host of the URL when set
+	Host        string
+	RandomAgent bool
+	// Data is sent as the body with every request
+	Data []byte
+	// AcceptEncoding is sent as the Accept-Encoding header, empty leaves it to the transport
+	// responses are decoded for any gzip, deflate or br Content-Encoding before checking the body
+	AcceptEncoding string
+	// HashBodies reports the fingerprint hash of every response body with the result
+	// so that responses can be compared between runs, each body is read to hash it
+	HashBodies bool
+	// Titles reports the <title> of HTML response bodies with the result
+	Titles bool
+	// MaxBody is the maximum number of bytes of the response body read for checks, 0 is unlimited
+	MaxBody int64
+	// Timeout bounds the whole request including reading the body, 0 is no timeout
+	Timeout        time.Duration
+	ConnectTimeout time.Duration
+	Retries        int
+	RetryBackoff   time.Duration
+	// RespectRetryAfter waits for the Retry-After of 429 and 503 responses and retries the request
+	RespectRetryAfter bool
+	Follow            bool
+	MaxRedirects      int
+	HTTP1             bool
+	// MaxIdleConns is the number of idle conns kept in total and for each host, 0 uses the transport default
+	MaxIdleConns int
+	// MaxConnsPerHost is the number of conns allowed to each host, 0 is unlimited
+	MaxConnsPerHost int
+	Insecure        bool
+	RootCAs         *x509.CertPool
+	Certificates    []tls.Certificate
+	// PerHost is the maximum number of concurrent requests to a single host, 0 is unlimited
+	PerHost int
+	// Rate is the maximum requests per second across all threads including retries, 0 is unlimited
+	Rate float64
+	// shared by all the requests of a scan to limit them to Rate
+	limiter *rate.Limiter
+	// each thread waits a random delay between DelayMin and DelayMax after a request
+	DelayMin, DelayMax time.Duration
+	Proxy              *url.URL
+	SourceIP           net.IP
+	// DryRun is called with each request instead of it being sent, no results are reported when set
+	DryRun func(req *http.Request)
+
+	// response checks, a response matching any check is denied
+
+	Statuses       StatusSet
+	Redirect       string
+	RedirectPrefix string
+	RedirectRegex  *regexp.Regexp
+	BodyContains   []string
+	// BodyAll requires all of BodyContains rather than any
+	BodyAll       bool
+	BodyRegex     *regexp.Regexp
+	BodyAbsent    string
+	IgnoreCase    bool
+	ContentLength *LengthComparison
+	MatchHeaders  []HeaderMatch
+	MatchJSON     []JSONMatch
+	MinTime       time.Duration
+	MaxTime       time.Duration
+	BaselineURL   string
+	// Baseline is the fingerprint of the BaselineURL body, see FetchBaseline
+	Baseline *Fingerprint
+	// Similarity to the Baseline from 0 to 1 at which a body is also denied, 0 only denies identical bodies
+	Similarity float64
+	// Invert reports responses matching any check as granted and all others as denied
+	// so the checks describe a response that has access
+	Invert bool
+	// Matchers are additional checks made after all of the other response checks
+	Matchers []Matcher
+	// Profiles requests each URL with the auth of every profile instead of the config auth
+	Profiles []Profile
+	// Expectations of the result for URLs with each profile, see NewExpectation
+	Expectations []Expectation
+	// StatusExpectations are the statuses each URL is expected to return, results are
+	// asserted to pass or fail and those that fail are unexpected
+	StatusExpectations map[string]StatusExpectation
+	// Diff requests each URL again without any auth and checks the responses are equivalent
+	Diff bool
+
+	// SaveDir is the directory each response is saved to, empty does not save responses
+	SaveDir string
+	// SaveOn are the results of the responses to save, empty saves all
+	SaveOn []string
+}
+
+// Returns a copy of the config with all the auth removed so that requests are anonymous
+func (c *Config) anonymous() *Config {
+	anon := *c
+	anon.Cookie = ""
+	anon.Cookies = nil
+	anon.Username = ""
+	anon.Password = ""
+	anon.Bearer = ""
+	anon.Headers = c.Headers.Clone()
+	anon.Headers.Del("Cookie")
+	anon.Headers.Del("Authorization")
+	return &anon
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"net/http"
+	"strings"
+	"time"
+)
+
+// FileCookie is a cookie read from a Netscape format cookie file
+type FileCookie struct {
+	Domain            string
+	IncludeSubdomains bool
+	Path              string
+	Secure            bool
+	Expires           int64
+	Name              string
+	Value             string
+}
+
+// Matches checks if the cookie should be sent with the request
+func (c *FileCookie) Matches(req *http.Request) bool {
+	if c.Secure && req.URL.Scheme != "https" {
+		return false
+	}
+
+	if c.Expires > 0 && c.Expires < time.Now().Unix() {
+		return false
+	}
+
+	if !strings.HasPrefix(req.URL.Path, c.Path) && !(c.Path == "/" && len(req.URL.Path) == 0) {
+		return false
+	}
+
+	host := strings.ToLower(req.URL.Hostname())
+	domain := strings.ToLower(strings.TrimPrefix(c.Domain, "."))
+	if host == domain {
+		return true
+	}
+	return (c.IncludeSubdomains || strings.HasPrefix(c.Domain, ".")) && strings.HasSuffix(host, "."+domain)
+}
@@ -0,0 +1,52 @@
+package scanner
+
+import (
+	"fmt"
+)
+
+// Minimum similarity of the bodies from 0 to 1 for responses to be equivalent
+const diffSimilarity = 0.9
+
+// Checks if the anonymous response is equivalent to the authenticated response, the bodies
+// must have the same normalized hash or be at least diffSimilarity similar
+// returns an err if either body could not be read
+func equivalent(auth, anon *PipelineContext) (bool, error) {
+	if auth.Response.StatusCode != anon.Response.StatusCode {
+		return false, nil
+	}
+
+	authBody, err := auth.Body()
+	if err != nil {
+		return false, err
+	}
+	anonBody, err := anon.Body()
+	if err != nil {
+		return false, err
+	}
+	authFp, anonFp := NewFingerprint(authBody), NewFingerprint(anonBody)
+	if authFp.Hash == anonFp.Hash {
+		return true, nil
+	}
+	return authFp.Similarity(anonFp) >= diffSimilarity, nil
+}
+
+// Reports on the authenticated and anonymous responses for a URL
+func reportDiff(results chan<- Result, auth, anon *PipelineContext, cfg *Config) {
+	for _, res := range []*PipelineContext{auth, anon} {
+		if res.Error != nil {
+			results <- newResult(res, ResultError, "", errorMessage(res.Error, cfg))
+			return
+		}
+	}
+
+	same, err := equivalent(auth, anon)
+	if err != nil {
+		results <- newResult(auth, ResultError, "", "Could not read body")
+		return
+	}
+	if same {
+		results <- newResult(anon, ResultGranted, "diff", fmt.Sprintf("GRANTED ACCESS Anonymous response equivalent to authenticated (%d / %d)", auth.Response.StatusCode, anon.Response.StatusCode))
+		return
+	}
+	results <- newResult(anon, ResultDenied, "diff", fmt.Sprintf("DENIED Anonymous response differs from authenticated (%d / %d)", auth.Response.StatusCode, anon.Response.StatusCode))
+}
@@ -0,0 +1,22 @@
+// Package scanner contains the pipeline used by gowac to check a stream of
+// URLs and report if access was granted or denied based on the checks in the
+// Config, it can be used to embed the checks in another program, see ExampleScan:
+//
+//	ctx := context.Background()
+//	token := os.Getenv("TOKEN")
+//	urls := make(chan string)
+//	go func() {
+//		defer close(urls)
+//		urls <- "https://example.com/admin"
+//	}()
+//
+//	cfg := scanner.Config{
+//		Threads:  10,
+//		Timeout:  5 * time.Second,
+//		Bearer:   token,
+//		Statuses: scanner.StatusSet{401: {}, 403: {}},
+//	}
+//	for r := range scanner.Scan(ctx, urls, cfg) {
+//		fmt.Println(r.URL, r.Result, r.Message)
+//	}
+package scanner
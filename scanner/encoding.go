@@ -0,0 +1,60 @@
+package scanner
+
+import (
+	"bufio"
+	"compress/flate"
+	"compress/gzip"
+	"compress/zlib"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/andybalholm/brotli"
+)
+
+// DefaultAcceptEncoding is the Accept-Encoding sent with requests, each of these is decoded before checking the body
+const DefaultAcceptEncoding = "gzip, deflate, br"
+
+// Wraps the body reader to decode each Content-Encoding of the response
+// encodings are listed in the order applied so are decoded in reverse
+func decodeBody(r io.Reader, header http.Header) (io.Reader, error) {
+	var encodings []string
+	for _, value := range header.Values("Content-Encoding") {
+		for _, e := range strings.Split(value, ",") {
+			if e = strings.ToLower(strings.TrimSpace(e)); len(e) > 0 {
+				encodings = append(encodings, e)
+			}
+		}
+	}
+
+	for i := len(encodings) - 1; i >= 0; i-- {
+		switch encodings[i] {
+		case "identity":
+		case "gzip", "x-gzip":
+			gz, err := gzip.NewReader(r)
+			if err != nil {
+				return nil, err
+			}
+			r = gz
+		case "deflate":
+			r = deflateReader(r)
+		case "br":
+			r = brotli.NewReader(r)
+		default:
+			return nil, fmt.Errorf("unsupported content encoding '%s'", encodings[i])
+		}
+	}
+	return r, nil
+}
+
+// Returns a reader for a deflate body, servers send either zlib wrapped or raw deflate data
+func deflateReader(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if hdr, err := br.Peek(2); err == nil && hdr[0]&0x0f == 8 && (uint16(hdr[0])<<8|uint16(hdr[1]))%31 == 0 {
+		if zr, err := zlib.NewReader(br); err == nil {
+			return zr
+		}
+	}
+	return flate.NewReader(br)
+}
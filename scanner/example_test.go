@@ -0,0 +1,43 @@
+package scanner_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+func ExampleScan() {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/admin" && r.Header.Get("Authorization") != "Bearer admin-token" {
+			w.WriteHeader(http.StatusForbidden)
+			return
+		}
+		fmt.Fprintln(w, "ok")
+	}))
+	defer srv.Close()
+
+	urls := make(chan string)
+	go func() {
+		defer close(urls)
+		urls <- srv.URL + "/admin"
+		urls <- srv.URL + "/public"
+	}()
+
+	cfg := scanner.Config{
+		Threads:  1,
+		Timeout:  5 * time.Second,
+		Bearer:   "user-token",
+		Statuses: scanner.StatusSet{401: {}, 403: {}},
+	}
+	for r := range scanner.Scan(context.Background(), urls, cfg) {
+		fmt.Println(strings.TrimPrefix(r.URL, srv.URL), r.Result, r.Message)
+	}
+	// Output:
+	// /admin denied DENIED Status Code (403) returned
+	// /public granted GRANTED ACCESS
+}
@@ -0,0 +1,113 @@
+package scanner
+
+import (
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+// Expectation of the result for URLs matching a glob when requested with a profile
+// must be created with NewExpectation
+type Expectation struct {
+	// Pattern is a glob matched against the whole URL where * matches any characters
+	// including slashes, a pattern starting with / is matched against the URL path
+	Pattern string
+	// Profile the expectation applies to, * or empty applies to every profile
+	Profile string
+	// Result expected, either ResultGranted or ResultDenied
+	Result string
+
+	re *regexp.Regexp
+}
+
+// NewExpectation compiles the pattern of an expectation
+func NewExpectation(pattern, profile, result string) (Expectation, error) {
+	if result != ResultGranted && result != ResultDenied {
+		return Expectation{}, fmt.Errorf("expected result '%s' is invalid, must be %s or %s", result, ResultGranted, ResultDenied)
+	}
+	expr := regexp.QuoteMeta(pattern)
+	expr = strings.ReplaceAll(expr, `\*`, ".*")
+	expr = strings.ReplaceAll(expr, `\?`, ".")
+	re, err := regexp.Compile("^" + expr + "$")
+	if err != nil {
+		return Expectation{}, fmt.Errorf("pattern '%s' is invalid: %v", pattern, err)
+	}
+	return Expectation{Pattern: pattern, Profile: profile, Result: result, re: re}, nil
+}
+
+// Checks if the expectation applies to the URL and profile
+func (e *Expectation) matches(rawURL, path, profile string) bool {
+	if len(e.Profile) > 0 && e.Profile != "*" && e.Profile != profile {
+		return false
+	}
+	if strings.HasPrefix(e.Pattern, "/") {
+		return e.re.MatchString(path)
+	}
+	return e.re.MatchString(rawURL)
+}
+
+// Sets the expected result on the result from the first matching expectation
+// and marks it unexpected when the result differs, errors are never unexpected
+func expect(r *Result, expectations []Expectation) {
+	path := r.URL
+	if u, err := url.Parse(r.URL); err == nil {
+		path = u.Path
+	}
+	for i := range expectations {
+		e := &expectations[i]
+		if !e.matches(r.URL, path, r.Profile) {
+			continue
+		}
+		r.Expected = e.Result
+		if r.Result != ResultError && r.Result != e.Result {
+			r.Unexpected = true
+			finding := "UNEXPECTED DENIAL"
+			if r.Result == ResultGranted {
+				finding = "UNEXPECTED ACCESS"
+			}
+			r.Message = fmt.Sprintf("%s expected %s: %s", finding, e.Result, r.Message)
+		}
+		return
+	}
+}
+
+// StatusExpectation is the status a URL is expected to return
+type StatusExpectation struct {
+	Statuses StatusSet
+	// Raw is the statuses as supplied to report in messages
+	Raw string
+}
+
+// Asserts the status of the result when one is expected for the URL marking it
+// unexpected when the status differs, errors always fail the assertion
+func expectStatus(r *Result, expectations map[string]StatusExpectation) {
+	e, ok := expectations[r.URL]
+	if !ok {
+		return
+	}
+	if r.Result != ResultError && e.Statuses.Contains(r.Status) {
+		r.Assertion = AssertionPass
+		r.Message = fmt.Sprintf("PASS expected status (%s): %s", e.Raw, r.Message)
+		return
+	}
+	r.Assertion = AssertionFail
+	r.Unexpected = true
+	r.Message = fmt.Sprintf("FAIL expected status (%s): %s", e.Raw, r.Message)
+}
+
+// Passes on the results setting the expected result and status of each, once in is closed out is closed
+func expectResults(in <-chan Result, cfg *Config) chan Result {
+	out := make(chan Result, cap(in))
+
+	go func() {
+		for r := range in {
+			expect(&r, cfg.Expectations)
+			expectStatus(&r, cfg.StatusExpectations)
+			out <- r
+		}
+		close(out)
+	}()
+
+	return out
+}
@@ -0,0 +1,332 @@
+package scanner
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// ExprMatcher matches responses using a boolean expression of comparisons such as
+// 'status==403 || (status==200 && body~"Forbidden")'
+//
+// The fields compared are status, length, latency, location, body and header.Name
+// with ==, != and ~ (regex) along with <, <=, > and >= for status, length and latency
+// comparisons are combined with &&, || and ! and grouped with parentheses
+type ExprMatcher struct {
+	raw  string
+	root exprNode
+}
+
+// ParseExpression parses the expression into a matcher
+func ParseExpression(raw string) (*ExprMatcher, error) {
+	tokens, err := tokenize(raw)
+	if err != nil {
+		return nil, fmt.Errorf("expression '%s' is invalid: %v", raw, err)
+	}
+	p := &exprParser{tokens: tokens}
+	root, err := p.or()
+	if err == nil && p.pos < len(p.tokens) {
+		err = fmt.Errorf("unexpected '%s'", p.tokens[p.pos].text)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("expression '%s' is invalid: %v", raw, err)
+	}
+	return &ExprMatcher{raw: raw, root: root}, nil
+}
+
+func (m *ExprMatcher) String() string { return m.raw }
+
+func (m *ExprMatcher) Rule() string { return "match" }
+
+func (m *ExprMatcher) Match(res *PipelineContext) (bool, string) {
+	if !m.root.eval(res) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Matches (%s)", m.raw)
+}
+
+// Node of the expression tree
+type exprNode interface {
+	eval(res *PipelineContext) bool
+}
+
+type orNode struct{ left, right exprNode }
+
+func (n *orNode) eval(res *PipelineContext) bool { return n.left.eval(res) || n.right.eval(res) }
+
+type andNode struct{ left, right exprNode }
+
+func (n *andNode) eval(res *PipelineContext) bool { return n.left.eval(res) && n.right.eval(res) }
+
+type notNode struct{ node exprNode }
+
+func (n *notNode) eval(res *PipelineContext) bool { return !n.node.eval(res) }
+
+// Comparison of a field of the response to a value
+type compareNode struct {
+	field string
+	// header name for header fields
+	header string
+	op     string
+	value  string
+	// value as a number for the numeric fields, latency is in nanoseconds
+	num int64
+	re  *regexp.Regexp
+}
+
+// Fields that can be compared other than headers
+var exprFields = map[string]bool{"status": true, "length": true, "latency": true, "location": true, "body": true}
+
+// Fields compared as numbers
+var numericFields = map[string]bool{"status": true, "length": true, "latency": true}
+
+// Returns the value of the field of the response as a number when numeric
+func (n *compareNode) resolve(res *PipelineContext) (string, int64) {
+	switch n.field {
+	case "status":
+		return strconv.Itoa(res.Response.StatusCode), int64(res.Response.StatusCode)
+	case "length":
+		length := res.Response.ContentLength
+		if length < 0 && hasBody(res) {
+			buf, _ := res.Body()
+			length = int64(len(buf))
+		}
+		return strconv.FormatInt(length, 10), length
+	case "latency":
+		return res.Elapsed.String(), int64(res.Elapsed)
+	case "location":
+		return res.Response.Header.Get("Location"), 0
+	case "body":
+		if !hasBody(res) {
+			return "", 0
+		}
+		buf, _ := res.Body()
+		return string(buf), 0
+	}
+	return strings.Join(res.Response.Header.Values(n.header), ", "), 0
+}
+
+func (n *compareNode) eval(res *PipelineContext) bool {
+	s, num := n.resolve(res)
+	if n.re != nil {
+		return n.re.MatchString(s)
+	}
+	if !numericFields[n.field] {
+		if n.op == "==" {
+			return s == n.value
+		}
+		return s != n.value
+	}
+	switch n.op {
+	case "==":
+		return num == n.num
+	case "!=":
+		return num != n.num
+	case "<":
+		return num < n.num
+	case "<=":
+		return num <= n.num
+	case ">":
+		return num > n.num
+	}
+	return num >= n.num
+}
+
+// Token of an expression, text is unquoted for strings
+type exprToken struct {
+	text   string
+	quoted bool
+}
+
+// Operators in the order they are matched so the longest is matched first
+var exprOperators = []string{"&&", "||", "==", "!=", "<=", ">=", "(", ")", "~", "<", ">", "!"}
+
+// Splits the expression into tokens
+func tokenize(raw string) ([]exprToken, error) {
+	var tokens []exprToken
+	for i := 0; i < len(raw); {
+		c := raw[i]
+		if unicode.IsSpace(rune(c)) {
+			i++
+			continue
+		}
+		if c == '"' || c == '\'' {
+			end := i + 1
+			var b strings.Builder
+			for ; end < len(raw) && raw[end] != c; end++ {
+				// allow the quote to be escaped
+				if raw[end] == '\\' && end+1 < len(raw) && raw[end+1] == c {
+					end++
+				}
+				b.WriteByte(raw[end])
+			}
+			if end >= len(raw) {
+				return nil, fmt.Errorf("unterminated string")
+			}
+			tokens = append(tokens, exprToken{text: b.String(), quoted: true})
+			i = end + 1
+			continue
+		}
+		op := ""
+		for _, o := range exprOperators {
+			if strings.HasPrefix(raw[i:], o) {
+				op = o
+				break
+			}
+		}
+		if len(op) > 0 {
+			tokens = append(tokens, exprToken{text: op})
+			i += len(op)
+			continue
+		}
+		end := i
+		for end < len(raw) && isWordByte(raw[end]) {
+			end++
+		}
+		if end == i {
+			return nil, fmt.Errorf("unexpected '%c'", c)
+		}
+		tokens = append(tokens, exprToken{text: raw[i:end]})
+		i = end
+	}
+	return tokens, nil
+}
+
+// Checks if the byte can be part of a field name or unquoted value
+func isWordByte(c byte) bool {
+	return c == '.' || c == '-' || c == '_' || c == '/' || c == ':' ||
+		(c >= 'a' && c <= 'z') || (c >= 'A' && c <= 'Z') || (c >= '0' && c <= '9')
+}
+
+// Recursive descent parser of the expression tokens
+type exprParser struct {
+	tokens []exprToken
+	pos    int
+}
+
+// Returns the next token when it is the operator and advances past it
+func (p *exprParser) accept(op string) bool {
+	if p.pos < len(p.tokens) && !p.tokens[p.pos].quoted && p.tokens[p.pos].text == op {
+		p.pos++
+		return true
+	}
+	return false
+}
+
+// Returns the next token and advances past it
+func (p *exprParser) next() (exprToken, error) {
+	if p.pos >= len(p.tokens) {
+		return exprToken{}, fmt.Errorf("unexpected end")
+	}
+	t := p.tokens[p.pos]
+	p.pos++
+	return t, nil
+}
+
+func (p *exprParser) or() (exprNode, error) {
+	left, err := p.and()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("||") {
+		right, err := p.and()
+		if err != nil {
+			return nil, err
+		}
+		left = &orNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) and() (exprNode, error) {
+	left, err := p.unary()
+	if err != nil {
+		return nil, err
+	}
+	for p.accept("&&") {
+		right, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		left = &andNode{left, right}
+	}
+	return left, nil
+}
+
+func (p *exprParser) unary() (exprNode, error) {
+	if p.accept("!") {
+		node, err := p.unary()
+		if err != nil {
+			return nil, err
+		}
+		return &notNode{node}, nil
+	}
+	if p.accept("(") {
+		node, err := p.or()
+		if err != nil {
+			return nil, err
+		}
+		if !p.accept(")") {
+			return nil, fmt.Errorf("missing ')'")
+		}
+		return node, nil
+	}
+	return p.comparison()
+}
+
+func (p *exprParser) comparison() (exprNode, error) {
+	field, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	n := &compareNode{field: strings.ToLower(field.text)}
+	if strings.HasPrefix(n.field, "header.") && len(n.field) > len("header.") {
+		n.field, n.header = "header", field.text[len("header."):]
+	} else if !exprFields[n.field] || field.quoted {
+		return nil, fmt.Errorf("unknown field '%s'", field.text)
+	}
+
+	op, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	switch op.text {
+	case "==", "!=", "~", "<", "<=", ">", ">=":
+		n.op = op.text
+	default:
+		return nil, fmt.Errorf("expected operator after '%s'", field.text)
+	}
+	value, err := p.next()
+	if err != nil {
+		return nil, err
+	}
+	n.value = value.text
+
+	if n.op == "~" {
+		if n.re, err = regexp.Compile(n.value); err != nil {
+			return nil, fmt.Errorf("regex '%s' is invalid", n.value)
+		}
+		return n, nil
+	}
+	if !numericFields[n.field] {
+		if n.op != "==" && n.op != "!=" {
+			return nil, fmt.Errorf("'%s' cannot be compared with '%s'", field.text, n.op)
+		}
+		return n, nil
+	}
+	if n.field == "latency" {
+		d, err := time.ParseDuration(n.value)
+		if err != nil {
+			return nil, fmt.Errorf("latency '%s' is invalid, must be a duration such as 500ms", n.value)
+		}
+		n.num = int64(d)
+		return n, nil
+	}
+	if n.num, err = strconv.ParseInt(n.value, 10, 64); err != nil {
+		return nil, fmt.Errorf("%s '%s' is invalid, must be a number", field.text, n.value)
+	}
+	return n, nil
+}
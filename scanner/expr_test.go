@@ -0,0 +1,94 @@
+package scanner
+
+import (
+	"net/http"
+	"testing"
+	"time"
+)
+
+func TestExprMatcher(t *testing.T) {
+	tests := []struct {
+		expr string
+		want bool
+	}{
+		{"status==200", true},
+		{"status==403", false},
+		{"status!=200", false},
+		{"status>=200 && status<300", true},
+		{"status>200 || status<=100", false},
+		{"length>10", true},
+		{"length==21", true},
+		{"latency>250ms", true},
+		{"latency<100ms", false},
+		{`body~"Forbid+en"`, true},
+		{`body~'^Denied'`, false},
+		{`body=="Access Forbidden here"`, true},
+		{`body!="nope"`, true},
+		{`location=="/login"`, true},
+		{"header.X-Frame-Options==DENY", true},
+		{`header.x-frame-options~"^DE"`, true},
+		{"header.Server==nginx", false},
+		// && binds tighter than || otherwise this would be false
+		{"status==200 || status==403 && body==nope", true},
+		{"(status==200 || status==403) && body==nope", false},
+		{"!status==403", true},
+		{"!(status==200)", false},
+		{"!!status==200", true},
+		{"!status==200 || status==200", true},
+		{"!(status==200 || status==403)", false},
+	}
+	for _, tt := range tests {
+		m, err := ParseExpression(tt.expr)
+		if err != nil {
+			t.Errorf("ParseExpression(%q) unexpected error: %v", tt.expr, err)
+			continue
+		}
+		res := &PipelineContext{
+			Method: http.MethodGet,
+			Response: &http.Response{
+				StatusCode:    http.StatusOK,
+				ContentLength: -1,
+				Header:        http.Header{"Location": {"/login"}, "X-Frame-Options": {"DENY"}},
+			},
+			Elapsed:  300 * time.Millisecond,
+			body:     []byte("Access Forbidden here"),
+			bodyRead: true,
+		}
+		if got, message := m.Match(res); got != tt.want {
+			t.Errorf("%q expected %v got %v", tt.expr, tt.want, got)
+		} else if got && message != "DENIED Matches ("+tt.expr+")" {
+			t.Errorf("%q unexpected message %q", tt.expr, message)
+		}
+	}
+}
+
+func TestParseExpressionInvalid(t *testing.T) {
+	for _, expr := range []string{
+		"",
+		"!",
+		"status",
+		"status==",
+		"status 200",
+		"status==abc",
+		"latency>5",
+		"body<3",
+		"location>=1",
+		"unknown==1",
+		`"status"==200`,
+		"header.==x",
+		"status==200 &&",
+		"&& status==200",
+		"status==200 ||",
+		"(status==200",
+		"status==200)",
+		"()",
+		"status==200 status==403",
+		`body~"unterminated`,
+		`body~"["`,
+		"status==200 #",
+	} {
+		if m, err := ParseExpression(expr); err == nil {
+			t.Errorf("ParseExpression(%q) expected an error got %v", expr, m)
+		}
+	}
+}
@@ -0,0 +1,84 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"hash/fnv"
+	"math/bits"
+	"net/http"
+)
+
+// Fingerprint of a response body used to compare responses
+type Fingerprint struct {
+	Hash string
+	// Length of the normalized body
+	Length int
+	// SimHash of the words in the body so that similar bodies have similar hashes
+	SimHash uint64
+}
+
+// Normalizes the body so insignificant whitespace differences are ignored
+func normalizeBody(body []byte) []byte {
+	return bytes.Join(bytes.Fields(body), []byte(" "))
+}
+
+// NewFingerprint creates a fingerprint from the normalized body
+func NewFingerprint(body []byte) Fingerprint {
+	normalized := normalizeBody(body)
+	sum := sha256.Sum256(normalized)
+	return Fingerprint{
+		Hash:    hex.EncodeToString(sum[:]),
+		Length:  len(normalized),
+		SimHash: simHash(body),
+	}
+}
+
+// Calculates the SimHash of the words in the body, each bit is set when
+// more of the word hashes have that bit set than not
+func simHash(body []byte) uint64 {
+	var weights [64]int
+	for _, word := range bytes.Fields(body) {
+		h := fnv.New64a()
+		h.Write(word)
+		sum := h.Sum64()
+		for i := 0; i < 64; i++ {
+			if sum&(1<<i) != 0 {
+				weights[i]++
+			} else {
+				weights[i]--
+			}
+		}
+	}
+
+	var hash uint64
+	for i, w := range weights {
+		if w > 0 {
+			hash |= 1 << i
+		}
+	}
+	return hash
+}
+
+// Similarity returns how similar the fingerprints are from 0 to 1 based on the
+// number of SimHash bits that are the same
+func (f Fingerprint) Similarity(other Fingerprint) float64 {
+	return 1 - float64(bits.OnesCount64(f.SimHash^other.SimHash))/64
+}
+
+// FetchBaseline requests the config BaselineURL and returns the fingerprint of its body
+func FetchBaseline(ctx context.Context, client *http.Client, cfg Config) (*Fingerprint, error) {
+	resp, err := requestURL(ctx, client, &Target{URL: cfg.BaselineURL}, &cfg)
+	if err != nil {
+		return nil, fmt.Errorf("could not request baseline URL: %v", err)
+	}
+	res := PipelineContext{URL: cfg.BaselineURL, Response: resp, maxBody: cfg.MaxBody}
+	body, err := res.Body()
+	if err != nil {
+		return nil, fmt.Errorf("could not read baseline URL body: %v", err)
+	}
+	fp := NewFingerprint(body)
+	return &fp, nil
+}
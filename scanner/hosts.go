@@ -0,0 +1,50 @@
+package scanner
+
+import (
+	"context"
+	"net/url"
+	"sync"
+)
+
+// Limits the number of concurrent requests made to each host
+type hostLimiter struct {
+	mu    sync.Mutex
+	limit int
+	hosts map[string]chan struct{}
+}
+
+func newHostLimiter(limit int) *hostLimiter {
+	return &hostLimiter{
+		limit: limit,
+		hosts: map[string]chan struct{}{},
+	}
+}
+
+// Returns the semaphore for the host of the raw URL creating it if required
+func (l *hostLimiter) semaphore(raw string) chan struct{} {
+	host := raw
+	if u, err := url.Parse(raw); err == nil {
+		host = u.Host
+	}
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	sem, ok := l.hosts[host]
+	if !ok {
+		sem = make(chan struct{}, l.limit)
+		l.hosts[host] = sem
+	}
+	return sem
+}
+
+// Waits for a slot for the host of the raw URL, the returned func releases
+// the slot, returns an err if the ctx is done before a slot is available
+func (l *hostLimiter) acquire(ctx context.Context, raw string) (func(), error) {
+	sem := l.semaphore(raw)
+	select {
+	case sem <- struct{}{}:
+		return func() { <-sem }, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
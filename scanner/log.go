@@ -0,0 +1,42 @@
+package scanner
+
+import (
+	"net/http"
+)
+
+// LogLevel of a diagnostic event, higher levels are more verbose
+type LogLevel int
+
+const (
+	// LevelInfo events are logged for each request made
+	LevelInfo LogLevel = iota + 1
+	// LevelDebug events include the request and response headers
+	LevelDebug
+)
+
+// Logger receives diagnostic events about the requests made, each event has
+// a message along with pairs of keys and values
+type Logger interface {
+	Log(level LogLevel, msg string, kv ...interface{})
+}
+
+// Logs the event when the config has a logger
+func (c *Config) log(level LogLevel, msg string, kv ...interface{}) {
+	if c.Logger != nil {
+		c.Logger.Log(level, msg, kv...)
+	}
+}
+
+// Headers containing secrets that are redacted when logged
+var secretHeaders = []string{"Authorization", "Cookie", "Proxy-Authorization", "Set-Cookie"}
+
+// Returns a copy of the headers with any secrets redacted so they are safe to log
+func redactHeaders(h http.Header) http.Header {
+	redacted := h.Clone()
+	for _, name := range secretHeaders {
+		if len(redacted.Values(name)) > 0 {
+			redacted.Set(name, "[redacted]")
+		}
+	}
+	return redacted
+}
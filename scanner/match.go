@@ -0,0 +1,208 @@
+package scanner
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// StatusSet is the set of status codes to check for
+type StatusSet map[int]struct{}
+
+// Contains checks if the status is in the set
+func (s StatusSet) Contains(status int) bool {
+	_, ok := s[status]
+	return ok
+}
+
+// ParseStatuses parses status codes supplied as a comma separated list, each entry can be
+// a code (401), a class (4xx) or an inclusive range (400-403)
+func ParseStatuses(raw string) (StatusSet, error) {
+	statuses := StatusSet{}
+	for _, s := range strings.Split(raw, ",") {
+		s = strings.ToLower(strings.TrimSpace(s))
+		var min, max int
+		var err error
+		if len(s) == 3 && strings.HasSuffix(s, "xx") {
+			min, err = strconv.Atoi(s[:1])
+			min *= 100
+			max = min + 99
+		} else if rawMin, rawMax, ok := strings.Cut(s, "-"); ok {
+			min, err = strconv.Atoi(rawMin)
+			if err == nil {
+				max, err = strconv.Atoi(rawMax)
+			}
+		} else {
+			min, err = strconv.Atoi(s)
+			max = min
+		}
+
+		if err != nil || min < 100 || max > 999 || min > max {
+			return nil, fmt.Errorf("status '%s' is invalid", s)
+		}
+		for status := min; status <= max; status++ {
+			statuses[status] = struct{}{}
+		}
+	}
+	return statuses, nil
+}
+
+// HeaderMatch is a response header to check for, a nil Value only checks the header is present
+type HeaderMatch struct {
+	Name  string
+	Value *regexp.Regexp
+}
+
+// Matches checks if the header is present and any value matches, returns the matched value
+func (m *HeaderMatch) Matches(header http.Header) (string, bool) {
+	values := header.Values(m.Name)
+	for _, v := range values {
+		if m.Value == nil || m.Value.MatchString(v) {
+			return v, true
+		}
+	}
+	return "", false
+}
+
+// ParseHeaderMatch parses a header match supplied in the format 'Name: regex'
+func ParseHeaderMatch(raw string) (HeaderMatch, error) {
+	name, value, err := ParseHeader(raw)
+	if err != nil {
+		return HeaderMatch{}, err
+	}
+	m := HeaderMatch{Name: name}
+	if len(value) > 0 {
+		m.Value, err = regexp.Compile(value)
+		if err != nil {
+			return HeaderMatch{}, fmt.Errorf("header match '%s' is invalid: %v", raw, err)
+		}
+	}
+	return m, nil
+}
+
+// JSONMatch is a field of a JSON body to check the value of
+type JSONMatch struct {
+	// Path is the dot separated path to the field, array elements are selected by index
+	Path  string
+	Value string
+}
+
+// Matches checks if the field in the decoded JSON has the value, strings are compared
+// as is and other values such as numbers, booleans and null by their JSON encoding
+func (m *JSONMatch) Matches(doc interface{}) bool {
+	v := doc
+	for _, key := range strings.Split(m.Path, ".") {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = node[key]; !ok {
+				return false
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(key)
+			if err != nil || i < 0 || i >= len(node) {
+				return false
+			}
+			v = node[i]
+		default:
+			return false
+		}
+	}
+	switch value := v.(type) {
+	case string:
+		return value == m.Value
+	case map[string]interface{}, []interface{}:
+		return false
+	default:
+		raw, err := json.Marshal(value)
+		return err == nil && string(raw) == m.Value
+	}
+}
+
+// ParseJSONMatch parses a JSON match supplied in the format 'path=value' such as 'error.code=403'
+func ParseJSONMatch(raw string) (JSONMatch, error) {
+	path, value, ok := strings.Cut(raw, "=")
+	path = strings.TrimSpace(path)
+	if !ok || len(path) == 0 || strings.HasPrefix(path, ".") || strings.HasSuffix(path, ".") || strings.Contains(path, "..") {
+		return JSONMatch{}, fmt.Errorf("JSON match '%s' is invalid, must be in format 'path=value'", raw)
+	}
+	return JSONMatch{Path: path, Value: value}, nil
+}
+
+// LengthComparison is a comparison of a length against a value
+type LengthComparison struct {
+	Op    string
+	Value int64
+}
+
+// Operators supported for length comparisons, longer operators must come first
+var lengthOps = []string{"<=", ">=", "!=", "<", ">", "="}
+
+// Matches checks if the length satisfies the comparison
+func (c *LengthComparison) Matches(length int64) bool {
+	switch c.Op {
+	case "<=":
+		return length <= c.Value
+	case ">=":
+		return length >= c.Value
+	case "!=":
+		return length != c.Value
+	case "<":
+		return length < c.Value
+	case ">":
+		return length > c.Value
+	default:
+		return length == c.Value
+	}
+}
+
+func (c *LengthComparison) String() string {
+	return c.Op + strconv.FormatInt(c.Value, 10)
+}
+
+// ParseLengthComparison parses a length comparison such as 0, <500 or >=1024, no operator is an exact match
+func ParseLengthComparison(raw string) (*LengthComparison, error) {
+	cmp := &LengthComparison{Op: "="}
+	value := strings.TrimSpace(raw)
+	for _, op := range lengthOps {
+		if strings.HasPrefix(value, op) {
+			cmp.Op = op
+			value = strings.TrimSpace(strings.TrimPrefix(value, op))
+			break
+		}
+	}
+
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || n < 0 {
+		return nil, fmt.Errorf("content length '%s' is invalid", raw)
+	}
+	cmp.Value = n
+	return cmp, nil
+}
+
+// Checks if the body contains the content optionally ignoring case
+func containsBody(body, content string, ignoreCase bool) bool {
+	if ignoreCase {
+		return strings.Contains(strings.ToLower(body), strings.ToLower(content))
+	}
+	return strings.Contains(body, content)
+}
+
+// Checks if the body contains any or all of the contents returning those contained
+func matchBody(body string, contents []string, all, ignoreCase bool) ([]string, bool) {
+	var contains []string
+	for _, c := range contents {
+		if containsBody(body, c, ignoreCase) {
+			contains = append(contains, c)
+			if !all {
+				break
+			}
+		} else if all {
+			return nil, false
+		}
+	}
+	return contains, len(contains) > 0
+}
@@ -0,0 +1,335 @@
+package scanner
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Matcher is a check of the response, a response matched by any of the matchers is denied
+type Matcher interface {
+	// Rule is the name of the check reported with denied results
+	Rule() string
+	// Match checks the response and returns true with a message when it is denied
+	// a matcher that fails to read the body returns false, the body error is then reported
+	Match(res *PipelineContext) (bool, string)
+}
+
+// HEAD responses have no body so the body checks are skipped
+func hasBody(res *PipelineContext) bool {
+	return res.Method != http.MethodHead
+}
+
+// StatusMatcher matches any of the status codes
+type StatusMatcher struct {
+	Statuses StatusSet
+}
+
+func (m *StatusMatcher) Rule() string { return "status" }
+
+func (m *StatusMatcher) Match(res *PipelineContext) (bool, string) {
+	if !m.Statuses.Contains(res.Response.StatusCode) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Status Code (%d) returned", res.Response.StatusCode)
+}
+
+// RedirectMatcher matches a Location header equal to the location
+type RedirectMatcher struct {
+	Location string
+}
+
+func (m *RedirectMatcher) Rule() string { return "redirect" }
+
+func (m *RedirectMatcher) Match(res *PipelineContext) (bool, string) {
+	locHdr := res.Response.Header.Get("Location")
+	if len(locHdr) == 0 || locHdr != m.Location {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Redirect (%s) returned", locHdr)
+}
+
+// RedirectPrefixMatcher matches a Location header starting with the prefix
+type RedirectPrefixMatcher struct {
+	Prefix string
+}
+
+func (m *RedirectPrefixMatcher) Rule() string { return "redirect-prefix" }
+
+func (m *RedirectPrefixMatcher) Match(res *PipelineContext) (bool, string) {
+	locHdr := res.Response.Header.Get("Location")
+	if len(locHdr) == 0 || !strings.HasPrefix(locHdr, m.Prefix) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Redirect (%s) starts with (%s)", locHdr, m.Prefix)
+}
+
+// RedirectRegexMatcher matches a Location header matching the regex
+type RedirectRegexMatcher struct {
+	Regex *regexp.Regexp
+}
+
+func (m *RedirectRegexMatcher) Rule() string { return "redirect-regex" }
+
+func (m *RedirectRegexMatcher) Match(res *PipelineContext) (bool, string) {
+	locHdr := res.Response.Header.Get("Location")
+	if len(locHdr) == 0 || !m.Regex.MatchString(locHdr) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Redirect (%s) matches regex (%s)", locHdr, m.Regex)
+}
+
+// JSONMatcher matches a JSON body with any of the field values
+// bodies that are not valid JSON do not match
+type JSONMatcher struct {
+	Fields []JSONMatch
+}
+
+func (m *JSONMatcher) Rule() string { return "json-match" }
+
+func (m *JSONMatcher) Match(res *PipelineContext) (bool, string) {
+	if !hasBody(res) {
+		return false, ""
+	}
+	buf, err := res.Body()
+	if err != nil {
+		return false, ""
+	}
+	dec := json.NewDecoder(bytes.NewReader(buf))
+	// numbers are kept as they were sent so they can be compared exactly
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return false, ""
+	}
+	for _, f := range m.Fields {
+		if f.Matches(doc) {
+			return true, fmt.Sprintf("DENIED JSON (%s=%s) returned", f.Path, f.Value)
+		}
+	}
+	return false, ""
+}
+
+// HeaderMatcher matches any of the response headers
+type HeaderMatcher struct {
+	Headers []HeaderMatch
+}
+
+func (m *HeaderMatcher) Rule() string { return "match-header" }
+
+func (m *HeaderMatcher) Match(res *PipelineContext) (bool, string) {
+	for _, h := range m.Headers {
+		if value, ok := h.Matches(res.Response.Header); ok {
+			return true, fmt.Sprintf("DENIED Header (%s: %s) returned", h.Name, value)
+		}
+	}
+	return false, ""
+}
+
+// BodyMatcher matches a body containing any of the strings, or all of them when All is set
+type BodyMatcher struct {
+	Contains   []string
+	All        bool
+	IgnoreCase bool
+}
+
+func (m *BodyMatcher) Rule() string { return "body" }
+
+func (m *BodyMatcher) Match(res *PipelineContext) (bool, string) {
+	if !hasBody(res) {
+		return false, ""
+	}
+	buf, err := res.Body()
+	if err != nil {
+		return false, ""
+	}
+	contains, ok := matchBody(string(buf), m.Contains, m.All, m.IgnoreCase)
+	if !ok {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Body contains (%s)", strings.Join(contains, ", "))
+}
+
+// BodyRegexMatcher matches a body matching the regex
+type BodyRegexMatcher struct {
+	Regex *regexp.Regexp
+}
+
+func (m *BodyRegexMatcher) Rule() string { return "body-regex" }
+
+func (m *BodyRegexMatcher) Match(res *PipelineContext) (bool, string) {
+	if !hasBody(res) {
+		return false, ""
+	}
+	buf, err := res.Body()
+	if err != nil || !m.Regex.Match(buf) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Body matches regex (%s)", m.Regex)
+}
+
+// BodyAbsentMatcher matches a body missing the string
+type BodyAbsentMatcher struct {
+	Absent     string
+	IgnoreCase bool
+}
+
+func (m *BodyAbsentMatcher) Rule() string { return "body-absent" }
+
+func (m *BodyAbsentMatcher) Match(res *PipelineContext) (bool, string) {
+	if !hasBody(res) {
+		return false, ""
+	}
+	buf, err := res.Body()
+	if err != nil || containsBody(string(buf), m.Absent, m.IgnoreCase) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Body missing (%s)", m.Absent)
+}
+
+// BaselineMatcher matches a body identical to the baseline
+type BaselineMatcher struct {
+	URL      string
+	Baseline Fingerprint
+}
+
+func (m *BaselineMatcher) Rule() string { return "baseline-url" }
+
+func (m *BaselineMatcher) Match(res *PipelineContext) (bool, string) {
+	if !hasBody(res) {
+		return false, ""
+	}
+	buf, err := res.Body()
+	if err != nil || NewFingerprint(buf).Hash != m.Baseline.Hash {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Body matches baseline (%s)", m.URL)
+}
+
+// SimilarityMatcher matches a body at least Similarity similar to the baseline
+type SimilarityMatcher struct {
+	URL        string
+	Baseline   Fingerprint
+	Similarity float64
+}
+
+func (m *SimilarityMatcher) Rule() string { return "similarity" }
+
+func (m *SimilarityMatcher) Match(res *PipelineContext) (bool, string) {
+	if !hasBody(res) {
+		return false, ""
+	}
+	buf, err := res.Body()
+	if err != nil {
+		return false, ""
+	}
+	sim := NewFingerprint(buf).Similarity(m.Baseline)
+	if sim < m.Similarity {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Body %.0f%% similar to baseline (%s)", sim*100, m.URL)
+}
+
+// ContentLengthMatcher matches a content length satisfying the comparison
+type ContentLengthMatcher struct {
+	Length *LengthComparison
+}
+
+func (m *ContentLengthMatcher) Rule() string { return "content-length" }
+
+func (m *ContentLengthMatcher) Match(res *PipelineContext) (bool, string) {
+	length := res.Response.ContentLength
+	// length is unknown for chunked responses so use the actual length
+	if length < 0 && hasBody(res) {
+		buf, err := res.Body()
+		if err != nil {
+			return false, ""
+		}
+		length = int64(len(buf))
+	}
+	if !m.Length.Matches(length) {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Content Length (%d) matches (%s)", length, m.Length)
+}
+
+// MinTimeMatcher matches a response quicker than the time
+type MinTimeMatcher struct {
+	Time time.Duration
+}
+
+func (m *MinTimeMatcher) Rule() string { return "min-time" }
+
+func (m *MinTimeMatcher) Match(res *PipelineContext) (bool, string) {
+	if res.Elapsed >= m.Time {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Response time (%s) less than (%s)", res.Elapsed.Round(time.Millisecond), m.Time)
+}
+
+// MaxTimeMatcher matches a response slower than the time
+type MaxTimeMatcher struct {
+	Time time.Duration
+}
+
+func (m *MaxTimeMatcher) Rule() string { return "max-time" }
+
+func (m *MaxTimeMatcher) Match(res *PipelineContext) (bool, string) {
+	if res.Elapsed <= m.Time {
+		return false, ""
+	}
+	return true, fmt.Sprintf("DENIED Response time (%s) greater than (%s)", res.Elapsed.Round(time.Millisecond), m.Time)
+}
+
+// Returns the matchers for the checks in the config in the order they are checked
+// followed by any additional Matchers
+func (c *Config) matchers() []Matcher {
+	var matchers []Matcher
+	if len(c.Statuses) > 0 {
+		matchers = append(matchers, &StatusMatcher{Statuses: c.Statuses})
+	}
+	if len(c.Redirect) > 0 {
+		matchers = append(matchers, &RedirectMatcher{Location: c.Redirect})
+	}
+	if len(c.RedirectPrefix) > 0 {
+		matchers = append(matchers, &RedirectPrefixMatcher{Prefix: c.RedirectPrefix})
+	}
+	if c.RedirectRegex != nil {
+		matchers = append(matchers, &RedirectRegexMatcher{Regex: c.RedirectRegex})
+	}
+	if len(c.MatchHeaders) > 0 {
+		matchers = append(matchers, &HeaderMatcher{Headers: c.MatchHeaders})
+	}
+	if len(c.BodyContains) > 0 {
+		matchers = append(matchers, &BodyMatcher{Contains: c.BodyContains, All: c.BodyAll, IgnoreCase: c.IgnoreCase})
+	}
+	if c.BodyRegex != nil {
+		matchers = append(matchers, &BodyRegexMatcher{Regex: c.BodyRegex})
+	}
+	if len(c.BodyAbsent) > 0 {
+		matchers = append(matchers, &BodyAbsentMatcher{Absent: c.BodyAbsent, IgnoreCase: c.IgnoreCase})
+	}
+	if len(c.MatchJSON) > 0 {
+		matchers = append(matchers, &JSONMatcher{Fields: c.MatchJSON})
+	}
+	if c.Baseline != nil {
+		matchers = append(matchers, &BaselineMatcher{URL: c.BaselineURL, Baseline: *c.Baseline})
+		if c.Similarity > 0 {
+			matchers = append(matchers, &SimilarityMatcher{URL: c.BaselineURL, Baseline: *c.Baseline, Similarity: c.Similarity})
+		}
+	}
+	if c.ContentLength != nil {
+		matchers = append(matchers, &ContentLengthMatcher{Length: c.ContentLength})
+	}
+	if c.MinTime > 0 {
+		matchers = append(matchers, &MinTimeMatcher{Time: c.MinTime})
+	}
+	if c.MaxTime > 0 {
+		matchers = append(matchers, &MaxTimeMatcher{Time: c.MaxTime})
+	}
+	return append(matchers, c.Matchers...)
+}
@@ -0,0 +1,78 @@
+package scanner
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/url"
+	"strings"
+	"syscall"
+)
+
+// Types of error for results that could not be checked
+const (
+	ErrorTimeout   = "timeout"
+	ErrorDNS       = "dns"
+	ErrorRefused   = "refused"
+	ErrorReset     = "reset"
+	ErrorTLS       = "tls"
+	ErrorRedirects = "redirects"
+	ErrorBody      = "body"
+	ErrorOther     = "other"
+)
+
+// Returns the type of error from a request
+func errorType(err error) string {
+	var dnsErr *net.DNSError
+	var netErr net.Error
+	var recordErr tls.RecordHeaderError
+	var authorityErr x509.UnknownAuthorityError
+	var certErr x509.CertificateInvalidError
+	var hostErr x509.HostnameError
+	switch {
+	case errors.Is(err, ErrTooManyRedirects):
+		return ErrorRedirects
+	case errors.As(err, &dnsErr):
+		return ErrorDNS
+	case errors.Is(err, context.DeadlineExceeded), errors.As(err, &netErr) && netErr.Timeout():
+		return ErrorTimeout
+	case errors.Is(err, syscall.ECONNREFUSED):
+		return ErrorRefused
+	case errors.Is(err, syscall.ECONNRESET), errors.Is(err, io.EOF), errors.Is(err, io.ErrUnexpectedEOF):
+		return ErrorReset
+	case errors.As(err, &recordErr), errors.As(err, &authorityErr), errors.As(err, &certErr), errors.As(err, &hostErr),
+		strings.Contains(err.Error(), "tls: "):
+		return ErrorTLS
+	}
+	return ErrorOther
+}
+
+// Returns the message reported for the err from a request
+func errorMessage(err error, cfg *Config) string {
+	switch errorType(err) {
+	case ErrorRedirects:
+		return fmt.Sprintf("Redirect loop, stopped after %d redirects", cfg.MaxRedirects)
+	case ErrorDNS:
+		var dnsErr *net.DNSError
+		errors.As(err, &dnsErr)
+		return fmt.Sprintf("DNS lookup failed for (%s)", dnsErr.Name)
+	case ErrorTimeout:
+		return "Request timed out"
+	case ErrorRefused:
+		return "Connection refused"
+	case ErrorReset:
+		return "Connection reset"
+	case ErrorTLS:
+		// report the cause without the method and URL of the request
+		var urlErr *url.Error
+		if errors.As(err, &urlErr) {
+			err = urlErr.Err
+		}
+		return fmt.Sprintf("TLS handshake failed: %q", err)
+	}
+	return fmt.Sprintf("Error making request: %q", err)
+}
@@ -0,0 +1,27 @@
+package scanner
+
+import (
+	"net/http"
+)
+
+// Profile is a named auth context each URL is requested with
+type Profile struct {
+	Name               string
+	Cookie             string
+	Bearer             string
+	Username, Password string
+	// Headers take precedence over the config Headers
+	Headers http.Header
+}
+
+// Returns a copy of the config with the auth replaced by the auth of the profile
+func (c *Config) withProfile(p Profile) *Config {
+	pc := c.anonymous()
+	pc.Cookie = p.Cookie
+	pc.Bearer = p.Bearer
+	pc.Username, pc.Password = p.Username, p.Password
+	for name, values := range p.Headers {
+		pc.Headers[name] = values
+	}
+	return pc
+}
@@ -0,0 +1,271 @@
+package scanner
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+)
+
+// ParseHeader parses a header supplied in the format 'Name: Value'
+func ParseHeader(raw string) (string, string, error) {
+	name, value, ok := strings.Cut(raw, ":")
+	name = strings.TrimSpace(name)
+	if !ok || len(name) == 0 {
+		return "", "", fmt.Errorf("header '%s' is invalid, must be provided as 'Name: Value'", raw)
+	}
+	return name, strings.TrimSpace(value), nil
+}
+
+// Configures the request based on the target and config
+func setupRequest(req *http.Request, t *Target, cfg *Config) {
+	// set target and custom headers, these take precedence over any of the other options
+	for name, values := range t.Header {
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+	for name, values := range cfg.Headers {
+		if len(t.Header.Values(name)) > 0 {
+			continue
+		}
+		for _, v := range values {
+			req.Header.Add(name, v)
+		}
+	}
+
+	// set the host the request is for, a Host header is only used by the request through req.Host
+	if host := req.Header.Get("Host"); len(host) > 0 {
+		req.Host = host
+		req.Header.Del("Host")
+	} else if len(cfg.Host) > 0 {
+		req.Host = cfg.Host
+	}
+
+	// set cookies header
+	if len(req.Header.Values("Cookie")) == 0 {
+		if len(cfg.Cookie) > 0 {
+			req.Header.Add("Cookie", cfg.Cookie)
+		}
+		for _, c := range cfg.Cookies {
+			if c.Matches(req) {
+				req.AddCookie(&http.Cookie{Name: c.Name, Value: c.Value})
+			}
+		}
+	}
+
+	// set basic auth header
+	if (len(cfg.Username) > 0 || len(cfg.Password) > 0) && len(req.Header.Values("Authorization")) == 0 {
+		req.SetBasicAuth(cfg.Username, cfg.Password)
+	}
+
+	// set bearer auth header
+	if len(cfg.Bearer) > 0 && len(req.Header.Values("Authorization")) == 0 {
+		req.Header.Set("Authorization", "Bearer "+cfg.Bearer)
+	}
+
+	// set user agent header
+	if len(req.Header.Values("User-Agent")) == 0 {
+		if len(cfg.UserAgent) > 0 {
+			req.Header.Set("User-Agent", cfg.UserAgent)
+		} else if cfg.RandomAgent {
+			req.Header.Set("User-Agent", userAgents[rand.Intn(len(userAgents))])
+		}
+	}
+
+	// set the encodings that are decoded, the transport only decodes gzip when it sets this itself
+	if len(cfg.AcceptEncoding) > 0 && len(req.Header.Values("Accept-Encoding")) == 0 {
+		req.Header.Set("Accept-Encoding", cfg.AcceptEncoding)
+	}
+
+	// set a content type when sending a body and one has not been supplied
+	if t.body(cfg) != nil && len(req.Header.Values("Content-Type")) == 0 {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+}
+
+// Response body that cancels the request context once closed
+type cancelBody struct {
+	io.ReadCloser
+	cancel context.CancelFunc
+}
+
+func (b *cancelBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.cancel()
+	return err
+}
+
+// Requests the target using the client and returns err or Response
+// the request is cancelled if ctx is done
+func requestURL(ctx context.Context, client *http.Client, t *Target, cfg *Config) (*http.Response, error) {
+	cancel := context.CancelFunc(func() {})
+	if cfg.Timeout > 0 {
+		ctx, cancel = context.WithTimeout(ctx, cfg.Timeout)
+	}
+	var body io.Reader
+	if data := t.body(cfg); data != nil {
+		body = bytes.NewReader(data)
+	}
+	req, err := http.NewRequestWithContext(ctx, t.method(cfg), t.URL, body)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	setupRequest(req, t, cfg)
+	if cfg.DryRun != nil {
+		cancel()
+		cfg.DryRun(req)
+		return nil, ErrDryRun
+	}
+	cfg.log(LevelDebug, "request", "method", req.Method, "url", req.URL, "headers", redactHeaders(req.Header))
+	resp, err := client.Do(req)
+	if err != nil {
+		cancel()
+		return nil, err
+	}
+	// the body is read after returning so only cancel once it has been closed
+	resp.Body = &cancelBody{ReadCloser: resp.Body, cancel: cancel}
+	return resp, nil
+}
+
+// Checks if the result of a request should be retried
+func retryable(resp *http.Response, err error) bool {
+	if err != nil {
+		return !errors.Is(err, ErrTooManyRedirects) && !errors.Is(err, context.Canceled) && !errors.Is(err, ErrDryRun)
+	}
+	return resp.StatusCode >= 500
+}
+
+// Maximum number of times a request is retried after the Retry-After delay
+const maxRetryAfterRetries = 5
+
+// Maximum delay waited for a Retry-After
+const maxRetryAfter = 5 * time.Minute
+
+// Returns the delay from the Retry-After header of a rate limited response
+// the header is either a number of seconds or a date
+func retryAfter(resp *http.Response) (time.Duration, bool) {
+	if resp == nil || (resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode != http.StatusServiceUnavailable) {
+		return 0, false
+	}
+	value := strings.TrimSpace(resp.Header.Get("Retry-After"))
+	if len(value) == 0 {
+		return 0, false
+	}
+	var delay time.Duration
+	if secs, err := strconv.Atoi(value); err == nil {
+		delay = time.Duration(secs) * time.Second
+	} else if date, err := http.ParseTime(value); err == nil {
+		delay = time.Until(date)
+	} else {
+		return 0, false
+	}
+	if delay < 0 {
+		delay = 0
+	}
+	if delay > maxRetryAfter {
+		delay = maxRetryAfter
+	}
+	return delay, true
+}
+
+// Number of requests currently being made
+var inFlight int64
+
+// InFlight returns the number of requests currently being made
+func InFlight() int64 {
+	return atomic.LoadInt64(&inFlight)
+}
+
+// Number of requests made including retries
+var requests int64
+
+// Requests returns the number of requests made including retries
+func Requests() int64 {
+	return atomic.LoadInt64(&requests)
+}
+
+// Requests the target retrying on failure with an exponential backoff plus jitter
+// rate limited responses are retried after the Retry-After delay when respected
+// returns the PipelineContext for the final attempt made
+func requestWithRetry(ctx context.Context, client *http.Client, t Target, cfg *Config) PipelineContext {
+	res := PipelineContext{
+		URL:      t.URL,
+		Method:   t.method(cfg),
+		maxBody:  cfg.MaxBody,
+		hashBody: cfg.HashBodies,
+		title:    cfg.Titles,
+	}
+	throttled := 0
+	for {
+		if cfg.limiter != nil {
+			if err := cfg.limiter.Wait(ctx); err != nil {
+				res.Error = err
+				return res
+			}
+		}
+		res.Attempts++
+		start := time.Now()
+		atomic.AddInt64(&inFlight, 1)
+		atomic.AddInt64(&requests, 1)
+		res.Response, res.Error = requestURL(ctx, client, &t, cfg)
+		atomic.AddInt64(&inFlight, -1)
+		res.Elapsed = time.Since(start)
+		if errors.Is(res.Error, ErrDryRun) {
+			return res
+		}
+		if res.Error != nil {
+			cfg.log(LevelInfo, "request failed", "method", res.Method, "url", t.URL, "attempt", res.Attempts, "elapsed", res.Elapsed, "error", res.Error)
+		} else {
+			cfg.log(LevelInfo, "response", "method", res.Method, "url", t.URL, "attempt", res.Attempts, "status", res.Response.StatusCode, "proto", res.Response.Proto, "elapsed", res.Elapsed)
+			cfg.log(LevelDebug, "response headers", "url", t.URL, "headers", redactHeaders(res.Response.Header))
+		}
+		if cfg.RespectRetryAfter && throttled < maxRetryAfterRetries {
+			if delay, ok := retryAfter(res.Response); ok {
+				throttled++
+				cfg.log(LevelInfo, "retry after", "method", res.Method, "url", t.URL, "status", res.Response.StatusCode, "delay", delay)
+				res.Response.Body.Close()
+				if err := sleep(ctx, delay); err != nil {
+					res.Response, res.Error = nil, err
+					return res
+				}
+				continue
+			}
+		}
+		if res.Attempts-throttled > cfg.Retries || !retryable(res.Response, res.Error) {
+			return res
+		}
+		if res.Error == nil {
+			res.Response.Body.Close()
+		}
+
+		backoff := cfg.RetryBackoff << (res.Attempts - throttled - 1)
+		if cfg.RetryBackoff > 0 {
+			backoff += time.Duration(rand.Int63n(int64(cfg.RetryBackoff)))
+		}
+		if err := sleep(ctx, backoff); err != nil {
+			res.Response, res.Error = nil, err
+			return res
+		}
+	}
+}
+
+// Sleeps for the duration returning early with an err if the ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,87 @@
+package scanner
+
+// Results of checking a URL
+const (
+	ResultGranted = "granted"
+	ResultDenied  = "denied"
+	ResultError   = "error"
+)
+
+// Assertions of the expected status of a result
+const (
+	AssertionPass = "pass"
+	AssertionFail = "fail"
+)
+
+// Result reported for each URL checked
+type Result struct {
+	URL           string `json:"url"`
+	Method        string `json:"method"`
+	Profile       string `json:"profile,omitempty"`
+	Status        int    `json:"status,omitempty"`
+	Result        string `json:"result"`
+	Rule          string `json:"rule,omitempty"`
+	Message       string `json:"message"`
+	ContentLength int64  `json:"content_length"`
+	LatencyMS     int64  `json:"latency_ms"`
+	Attempts      int    `json:"attempts"`
+	// ErrorType is the type of error for error results such as dns, refused or tls
+	ErrorType string `json:"error_type,omitempty"`
+	// Expected is the result expected from the first matching expectation
+	Expected string `json:"expected,omitempty"`
+	// Unexpected is set when the result differs from the expected result
+	Unexpected bool `json:"unexpected,omitempty"`
+	// Assertion is pass or fail when a status is expected for the URL
+	Assertion string `json:"assertion,omitempty"`
+	// Truncated is set when the checks only used the first MaxBody bytes of the body
+	Truncated bool `json:"truncated,omitempty"`
+	// BodyHash is the fingerprint hash of the body when hashing bodies
+	BodyHash string `json:"body_hash,omitempty"`
+	// Title is the <title> of an HTML body when reporting titles
+	Title     string `json:"title,omitempty"`
+	Timestamp string `json:"timestamp,omitempty"`
+}
+
+// Creates a result for the PipelineContext with the rule that was matched
+// the result is recorded on the PipelineContext so the response can be saved
+func newResult(res *PipelineContext, result, rule, message string) Result {
+	res.result = result
+	r := Result{
+		URL:       res.URL,
+		Method:    res.Method,
+		Profile:   res.Profile,
+		Result:    result,
+		Rule:      rule,
+		Message:   message,
+		LatencyMS: res.Elapsed.Milliseconds(),
+		Attempts:  res.Attempts,
+	}
+	if result == ResultError {
+		if res.Error != nil {
+			r.ErrorType = errorType(res.Error)
+		} else if res.bodyErr != nil {
+			r.ErrorType = ErrorBody
+		}
+	}
+	if res.Response != nil {
+		r.Status = res.Response.StatusCode
+		if res.hashBody && hasBody(res) {
+			if body, err := res.Body(); err == nil {
+				r.BodyHash = NewFingerprint(body).Hash
+			}
+		}
+		if res.title && hasBody(res) {
+			if body, err := res.Body(); err == nil {
+				r.Title = extractTitle(body)
+			}
+		}
+
+		r.ContentLength = res.Response.ContentLength
+		// use the actual length when the body has been read
+		if res.bodyRead && res.bodyErr == nil && !res.truncated {
+			r.ContentLength = int64(len(res.body))
+		}
+	}
+	r.Truncated = res.truncated
+	return r
+}
@@ -0,0 +1,61 @@
+package scanner
+
+import (
+	"bufio"
+	"fmt"
+	"hash/fnv"
+	"os"
+	"path/filepath"
+)
+
+// Returns the file name a response is saved to, a hash of the method, URL and profile
+// so that each request made for a URL is saved separately
+func saveName(res *PipelineContext) string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s %s %s %t", res.Method, res.URL, res.Profile, res.Anonymous)
+	return fmt.Sprintf("%016x.http", h.Sum64())
+}
+
+// Checks if responses with the result should be saved
+func (c *Config) saves(result string) bool {
+	if len(c.SaveDir) == 0 || len(result) == 0 {
+		return false
+	}
+	if len(c.SaveOn) == 0 {
+		return true
+	}
+	for _, on := range c.SaveOn {
+		if on == result {
+			return true
+		}
+	}
+	return false
+}
+
+// Writes the request line followed by the status line, headers and body of the response to a file in the save dir
+// the body is read only when a check has not already read it, at most MaxBody bytes are saved
+func saveResponse(res *PipelineContext, dir string) error {
+	body, err := res.Body()
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Create(filepath.Join(dir, saveName(res)))
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	w := bufio.NewWriter(f)
+	fmt.Fprintf(w, "%s %s\r\n", res.Method, res.URL)
+	fmt.Fprintf(w, "%s %s\r\n", res.Response.Proto, res.Response.Status)
+	if err := res.Response.Header.Write(w); err != nil {
+		return err
+	}
+	w.WriteString("\r\n")
+	w.Write(body)
+	if err := w.Flush(); err != nil {
+		return err
+	}
+	return f.Close()
+}
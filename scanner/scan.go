@@ -0,0 +1,321 @@
+package scanner
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math/rand"
+	"net/http"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/stavinski/gowac/utils"
+	"golang.org/x/time/rate"
+)
+
+// The context used in the pipeline
+type PipelineContext struct {
+	URL      string
+	Method   string
+	Response *http.Response
+	Error    error
+	Attempts int
+	// time taken for the final attempt
+	Elapsed time.Duration
+	// name of the profile the request was made with
+	Profile string
+	// used in diff mode to identify the anonymous request and the pair it belongs to
+	Anonymous bool
+	pair      uint64
+
+	// response body once read, at most maxBody bytes are read when maxBody > 0
+	body      []byte
+	bodyErr   error
+	bodyRead  bool
+	maxBody   int64
+	truncated bool
+	// the fingerprint hash of the body is reported with the result
+	hashBody bool
+	// the <title> of the body is reported with the result
+	title bool
+	// result reported for the response, empty when it was not reported
+	result string
+}
+
+// Body reads, decodes and closes the response body, the body is only read once so can be
+// called by each of the checks that require it
+func (c *PipelineContext) Body() ([]byte, error) {
+	if !c.bodyRead {
+		c.bodyRead = true
+		defer c.Response.Body.Close()
+		var r io.Reader
+		if r, c.bodyErr = decodeBody(c.Response.Body, c.Response.Header); c.bodyErr != nil {
+			return nil, c.bodyErr
+		}
+		if c.maxBody > 0 {
+			// read an extra byte to know if the body was truncated
+			r = io.LimitReader(r, c.maxBody+1)
+		}
+		c.body, c.bodyErr = io.ReadAll(r)
+		if c.maxBody > 0 && int64(len(c.body)) > c.maxBody {
+			c.body = c.body[:c.maxBody]
+			c.truncated = true
+		}
+	}
+	return c.body, c.bodyErr
+}
+
+// Performs necessary cleanup on the PipelineContext from the chan
+// Saves and closes the response body, the chan is always drained even once cancelled
+// so that every response is closed
+func cleanup(ctx <-chan PipelineContext, cfg *Config) <-chan struct{} {
+	done := make(chan struct{})
+
+	go func() {
+		for c := range ctx {
+			if c.Error != nil {
+				continue
+			}
+			if cfg.saves(c.result) {
+				if err := saveResponse(&c, cfg.SaveDir); err != nil {
+					cfg.log(LevelInfo, "save failed", "url", c.URL, "err", err)
+				}
+			}
+			c.Response.Body.Close()
+		}
+		close(done)
+	}()
+
+	return done
+}
+
+// Parses the context chan to calculate and report on
+// results are no longer reported once ctx is done but are still passed on for cleanup
+func parse(ctx context.Context, in <-chan PipelineContext, results chan<- Result, cfg *Config) chan PipelineContext {
+	out := make(chan PipelineContext, cfg.Buffer)
+
+	go func() {
+		// diff mode pairs waiting on the other request
+		pending := map[uint64]PipelineContext{}
+		matchers := cfg.matchers()
+
+		for res := range in {
+			// requests cancelled on shutdown or not sent in a dry run are not reported
+			if ctx.Err() != nil || errors.Is(res.Error, context.Canceled) || errors.Is(res.Error, ErrDryRun) {
+				out <- res
+				continue
+			}
+
+			if cfg.Diff {
+				other, ok := pending[res.pair]
+				if !ok {
+					pending[res.pair] = res
+					continue
+				}
+				delete(pending, res.pair)
+				auth, anon := res, other
+				if auth.Anonymous {
+					auth, anon = anon, auth
+				}
+				reportDiff(results, &auth, &anon, cfg)
+				out <- auth
+				out <- anon
+				continue
+			}
+
+			if res.Error != nil {
+				rule := ""
+				if errors.Is(res.Error, ErrTooManyRedirects) {
+					rule = "max-redirects"
+				}
+				results <- newResult(&res, ResultError, rule, errorMessage(res.Error, cfg))
+				out <- res
+				continue
+			}
+
+			reported := false
+			for _, m := range matchers {
+				if ok, message := m.Match(&res); ok {
+					if cfg.Invert {
+						results <- newResult(&res, ResultGranted, m.Rule(), "GRANTED ACCESS "+strings.TrimPrefix(message, "DENIED "))
+					} else {
+						results <- newResult(&res, ResultDenied, m.Rule(), message)
+					}
+					reported = true
+					break
+				}
+				if res.bodyErr != nil {
+					results <- newResult(&res, ResultError, "", "Could not read body")
+					reported = true
+					break
+				}
+			}
+			if !reported {
+				if cfg.Invert {
+					results <- newResult(&res, ResultDenied, "", "DENIED No checks matched")
+				} else {
+					results <- newResult(&res, ResultGranted, "", "GRANTED ACCESS")
+				}
+			}
+			out <- res
+		}
+
+		// the other request of a pair may never arrive such as when cancelled so these
+		// are passed on for cleanup without being reported
+		for _, res := range pending {
+			out <- res
+		}
+		close(out)
+	}()
+
+	return out
+}
+
+// Target along with its position in the input
+type queued struct {
+	Target
+	seq uint64
+}
+
+// Used to identify the pairs of requests made in diff mode
+var pairs uint64
+
+// Requests made for a target along with its position in the input
+type batch struct {
+	seq  uint64
+	sent []PipelineContext
+}
+
+// Returns the work done for each target by the worker pool, hosts is shared
+// between all the workers and may be nil when not limiting
+// in diff mode an additional anonymous request is sent for each URL
+// with profiles a request is sent for each URL under every profile
+func send(ctx context.Context, client *http.Client, hosts *hostLimiter, cfg *Config) func(queued) batch {
+	anonCfg := cfg.anonymous()
+	profileCfgs := make([]*Config, len(cfg.Profiles))
+	for i, p := range cfg.Profiles {
+		profileCfgs[i] = cfg.withProfile(p)
+	}
+
+	return func(q queued) batch {
+		b := batch{seq: q.seq}
+		t := q.Target
+		release := func() {}
+		if hosts != nil {
+			var err error
+			if release, err = hosts.acquire(ctx, t.URL); err != nil {
+				return b
+			}
+		}
+		switch {
+		case len(profileCfgs) > 0:
+			for i, pc := range profileCfgs {
+				res := requestWithRetry(ctx, client, t, pc)
+				res.Profile = cfg.Profiles[i].Name
+				b.sent = append(b.sent, res)
+			}
+		case cfg.Diff:
+			res := requestWithRetry(ctx, client, t, cfg)
+			res.pair = atomic.AddUint64(&pairs, 1)
+			anon := requestWithRetry(ctx, client, t.anonymous(), anonCfg)
+			anon.Anonymous = true
+			anon.pair = res.pair
+			b.sent = append(b.sent, anon, res)
+		default:
+			b.sent = append(b.sent, requestWithRetry(ctx, client, t, cfg))
+		}
+		release()
+
+		// each worker waits between the targets it requests
+		if cfg.DelayMax > 0 {
+			delay := cfg.DelayMin
+			if cfg.DelayMax > cfg.DelayMin {
+				delay += time.Duration(rand.Int63n(int64(cfg.DelayMax - cfg.DelayMin)))
+			}
+			sleep(ctx, delay)
+		}
+		return b
+	}
+}
+
+// Sends each of the requests from the batches on a single chan
+// every request is sent even once cancelled so that each response is closed
+func flatten(in <-chan batch, size int) chan PipelineContext {
+	out := make(chan PipelineContext, size)
+
+	go func() {
+		for b := range in {
+			for _, res := range b.sent {
+				out <- res
+			}
+		}
+		close(out)
+	}()
+
+	return out
+}
+
+// Scan requests each of the URLs from the chan and returns the results on a chan
+// which is closed once all URLs have been checked, the results chan must be drained
+// once ctx is done no more requests are sent and in-flight requests are not reported
+func Scan(ctx context.Context, urls <-chan string, cfg Config) <-chan Result {
+	targets := make(chan Target)
+	go func() {
+		defer close(targets)
+		for url := range urls {
+			select {
+			case targets <- Target{URL: url}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ScanTargets(ctx, targets, cfg)
+}
+
+// ScanTargets is the same as Scan with request options specific to each target
+func ScanTargets(ctx context.Context, targets <-chan Target, cfg Config) <-chan Result {
+	if cfg.Threads < 1 {
+		cfg.Threads = 1
+	}
+	if cfg.Buffer < 1 {
+		cfg.Buffer = cfg.Threads
+	}
+	if len(cfg.Method) == 0 {
+		cfg.Method = http.MethodGet
+	}
+	client := cfg.Client
+	if client == nil {
+		client = NewClient(cfg)
+	}
+
+	if cfg.Rate > 0 {
+		cfg.limiter = rate.NewLimiter(rate.Limit(cfg.Rate), 1)
+	}
+	var hosts *hostLimiter
+	if cfg.PerHost > 0 {
+		hosts = newHostLimiter(cfg.PerHost)
+	}
+
+	results := make(chan Result, cfg.Buffer)
+	go func() {
+		var seq uint64
+		queue := utils.Map(ctx, targets, func(t Target) queued {
+			q := queued{Target: t, seq: seq}
+			seq++
+			return q
+		})
+		batches := utils.WorkerPoolBuffered(ctx, cfg.Buffer, cfg.Threads, queue, send(ctx, client, hosts, &cfg))
+		if cfg.Ordered {
+			batches = utils.MergeOrdered(ctx, cfg.Buffer, func(b batch) uint64 { return b.seq }, batches)
+		}
+		<-cleanup(parse(ctx, flatten(batches, cfg.Buffer), results, &cfg), &cfg)
+		close(results)
+	}()
+
+	if len(cfg.Expectations) > 0 || len(cfg.StatusExpectations) > 0 {
+		return expectResults(results, &cfg)
+	}
+	return results
+}
@@ -0,0 +1,205 @@
+package scanner
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// Each URL that errors is reported once whether it timed out or failed for another reason
+func TestScanErrorReportedOnce(t *testing.T) {
+	slow := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-time.After(time.Second):
+		case <-r.Context().Done():
+		}
+	}))
+	defer slow.Close()
+	closed := httptest.NewServer(http.NotFoundHandler())
+	closed.Close()
+
+	timeoutURL := slow.URL + "/slow"
+	refusedURL := closed.URL + "/refused"
+	urls := make(chan string)
+	go func() {
+		defer close(urls)
+		urls <- timeoutURL
+		urls <- refusedURL
+	}()
+	cfg := Config{
+		Threads:  2,
+		Timeout:  100 * time.Millisecond,
+		Statuses: StatusSet{http.StatusForbidden: {}},
+	}
+
+	results := map[string][]Result{}
+	for r := range Scan(context.Background(), urls, cfg) {
+		results[r.URL] = append(results[r.URL], r)
+	}
+	if len(results) != 2 {
+		t.Fatalf("expected results for 2 URLs got %d", len(results))
+	}
+	for url, want := range map[string]string{timeoutURL: ErrorTimeout, refusedURL: ErrorRefused} {
+		got := results[url]
+		if len(got) != 1 {
+			t.Errorf("expected 1 result for %s got %d", url, len(got))
+			continue
+		}
+		if got[0].Result != ResultError || got[0].ErrorType != want {
+			t.Errorf("expected %s error for %s got %s %s: %s", want, url, got[0].Result, got[0].ErrorType, got[0].Message)
+		}
+	}
+}
+
+// Transport that counts the response bodies returned and closed
+type bodyTracker struct {
+	mu     sync.Mutex
+	opened int
+	closed int
+}
+
+type trackedBody struct {
+	io.Reader
+	tracker *bodyTracker
+	once    sync.Once
+}
+
+func (b *trackedBody) Close() error {
+	b.once.Do(func() {
+		b.tracker.mu.Lock()
+		b.tracker.closed++
+		b.tracker.mu.Unlock()
+	})
+	return nil
+}
+
+// the context is not checked so responses arrive as though they completed just as the scan was cancelled
+func (t *bodyTracker) RoundTrip(req *http.Request) (*http.Response, error) {
+	time.Sleep(5 * time.Millisecond)
+	t.mu.Lock()
+	t.opened++
+	t.mu.Unlock()
+	return &http.Response{
+		StatusCode: http.StatusOK,
+		Header:     http.Header{},
+		Body:       &trackedBody{Reader: strings.NewReader("ok"), tracker: t},
+		Request:    req,
+	}, nil
+}
+
+// Every response body is closed when the scan is cancelled part way through
+func TestScanCancelledClosesBodies(t *testing.T) {
+	tests := []struct {
+		name    string
+		ordered bool
+		diff    bool
+	}{
+		{"unordered", false, false},
+		{"ordered", true, false},
+		{"diff", false, true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tracker := &bodyTracker{}
+			ctx, cancel := context.WithCancel(context.Background())
+			defer cancel()
+
+			targets := make(chan Target)
+			go func() {
+				defer close(targets)
+				for i := 0; i < 500; i++ {
+					select {
+					case targets <- Target{URL: fmt.Sprintf("http://example.com/%d", i)}:
+					case <-ctx.Done():
+						return
+					}
+				}
+			}()
+			cfg := Config{
+				Client:   &http.Client{Transport: tracker},
+				Threads:  20,
+				Buffer:   50,
+				Ordered:  tt.ordered,
+				Diff:     tt.diff,
+				Cookie:   "session=1",
+				Statuses: StatusSet{http.StatusForbidden: {}},
+			}
+			received := 0
+			for range ScanTargets(ctx, targets, cfg) {
+				received++
+				if received == 10 {
+					cancel()
+				}
+			}
+
+			tracker.mu.Lock()
+			defer tracker.mu.Unlock()
+			if tracker.opened == 0 {
+				t.Fatal("expected responses before cancelling")
+			}
+			if tracker.closed != tracker.opened {
+				t.Errorf("expected all %d bodies to be closed got %d", tracker.opened, tracker.closed)
+			}
+		})
+	}
+}
+
+// Compares the memory used by the pipeline with different buffer sizes
+// A diff response whose pair never arrives is still passed on for cleanup
+func TestParseUnpairedDiffPassedOn(t *testing.T) {
+	in := make(chan PipelineContext, 1)
+	in <- PipelineContext{Anonymous: true, pair: 1}
+	close(in)
+	results := make(chan Result, 1)
+
+	var got []PipelineContext
+	for res := range parse(context.Background(), in, results, &Config{Diff: true}) {
+		got = append(got, res)
+	}
+	if len(got) != 1 || got[0].pair != 1 {
+		t.Fatalf("expected the unpaired response to be passed on got %d", len(got))
+	}
+	if len(results) != 0 {
+		t.Errorf("expected no results for an unpaired response got %d", len(results))
+	}
+}
+
+func BenchmarkScan(b *testing.B) {
+	body := strings.Repeat("denied ", 1024)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+		fmt.Fprint(w, body)
+	}))
+	defer srv.Close()
+
+	for _, buffer := range []int{1, 10, 100, 1000} {
+		b.Run(fmt.Sprintf("buffer=%d", buffer), func(b *testing.B) {
+			b.ReportAllocs()
+			urls := make(chan string)
+			go func() {
+				defer close(urls)
+				for i := 0; i < b.N; i++ {
+					urls <- fmt.Sprintf("%s/%d", srv.URL, i)
+				}
+			}()
+			cfg := Config{
+				Threads: 10,
+				Buffer:  buffer,
+				Timeout: 5 * time.Second,
+				// a body check so that each body is read
+				BodyContains: []string{"denied"},
+			}
+			for r := range Scan(context.Background(), urls, cfg) {
+				if r.Result != ResultDenied {
+					b.Fatalf("expected denied result for %s got %s: %s", r.URL, r.Result, r.Message)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,40 @@
+package scanner
+
+import (
+	"net/http"
+)
+
+// Target is a URL to check along with any request options specific to it
+type Target struct {
+	URL string
+	// Method overrides the config Method when set
+	Method string
+	// Header takes precedence over the config Headers
+	Header http.Header
+	// Body overrides the config Data when not nil
+	Body []byte
+}
+
+// Returns the method to use for the target with the config
+func (t *Target) method(cfg *Config) string {
+	if len(t.Method) > 0 {
+		return t.Method
+	}
+	return cfg.Method
+}
+
+// Returns the body to send for the target with the config
+func (t *Target) body(cfg *Config) []byte {
+	if t.Body != nil {
+		return t.Body
+	}
+	return cfg.Data
+}
+
+// Returns a copy of the target with the auth headers removed so that requests are anonymous
+func (t Target) anonymous() Target {
+	t.Header = t.Header.Clone()
+	t.Header.Del("Cookie")
+	t.Header.Del("Authorization")
+	return t
+}
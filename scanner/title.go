@@ -0,0 +1,35 @@
+package scanner
+
+import (
+	"bytes"
+	"html"
+	"strings"
+)
+
+// Maximum number of characters of a title reported
+const maxTitle = 100
+
+// Extracts the text of the first <title> element from an HTML body
+// the text is unescaped with whitespace collapsed, empty when there is no title
+func extractTitle(body []byte) string {
+	lower := bytes.ToLower(body)
+	start := bytes.Index(lower, []byte("<title"))
+	if start < 0 {
+		return ""
+	}
+	// skip any attributes on the element
+	open := bytes.IndexByte(lower[start:], '>')
+	if open < 0 {
+		return ""
+	}
+	start += open + 1
+	end := bytes.Index(lower[start:], []byte("</title"))
+	if end < 0 {
+		return ""
+	}
+	title := strings.Join(strings.Fields(html.UnescapeString(string(body[start:start+end]))), " ")
+	if runes := []rune(title); len(runes) > maxTitle {
+		title = string(runes[:maxTitle]) + "..."
+	}
+	return title
+}
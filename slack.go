@@ -0,0 +1,94 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Slack only allows roughly one message per second for each webhook
+const slackInterval = time.Second
+
+// Colors of the Slack attachments for each result
+var slackColors = map[string]string{
+	scanner.ResultGranted: "#2eb886",
+	scanner.ResultDenied:  "#e01e5a",
+	scanner.ResultError:   "#daa038",
+}
+
+// Payload posted to a Slack incoming webhook
+type slackMessage struct {
+	Text        string            `json:"text,omitempty"`
+	Attachments []slackAttachment `json:"attachments,omitempty"`
+}
+
+type slackAttachment struct {
+	Color  string       `json:"color"`
+	Blocks []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type string    `json:"type"`
+	Text slackText `json:"text"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+// Formats the results as a Slack message with an attachment for each result
+func slackPayload(results []scanner.Result) ([]byte, error) {
+	msg := slackMessage{}
+	for _, r := range results {
+		text := fmt.Sprintf("*%s* `%s` <%s|%s>\n%s", r.Result, r.Method, r.URL, r.URL, r.Message)
+		msg.Attachments = append(msg.Attachments, slackAttachment{
+			Color:  slackColors[r.Result],
+			Blocks: []slackBlock{{Type: "section", Text: slackText{Type: "mrkdwn", Text: text}}},
+		})
+	}
+	return json.Marshal(msg)
+}
+
+// Posts results to a Slack incoming webhook followed by a summary once closed
+type slackWriter struct {
+	*webhookWriter
+	stats *stats
+}
+
+func newSlackWriter(url string, events []string, batch int, summary *stats) *slackWriter {
+	return &slackWriter{
+		webhookWriter: startWebhookWriter(&webhookWriter{
+			url:      url,
+			events:   events,
+			batch:    batch,
+			encode:   slackPayload,
+			interval: slackInterval,
+		}),
+		stats: summary,
+	}
+}
+
+// Sends any remaining results then the summary of the run
+func (s *slackWriter) Close() error {
+	if err := s.webhookWriter.Close(); err != nil {
+		return err
+	}
+	// deliveries have finished so the last post time is safe to use
+	s.wait()
+	s.stats.mu.Lock()
+	text := fmt.Sprintf("gowac finished checking %d URLs: %d granted, %d denied, %d errors",
+		s.stats.total, s.stats.results[scanner.ResultGranted], s.stats.results[scanner.ResultDenied], s.stats.results[scanner.ResultError])
+	s.stats.mu.Unlock()
+	payload, err := json.Marshal(slackMessage{Text: text})
+	if err != nil {
+		return err
+	}
+	if err := s.post(payload); err != nil {
+		fmt.Fprintf(os.Stderr, "[!] could not deliver summary to Slack: %v\n", err)
+	}
+	return nil
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Response recorded for a URL in a snapshot
+type snapshotEntry struct {
+	URL           string `json:"url"`
+	Method        string `json:"method"`
+	Profile       string `json:"profile,omitempty"`
+	Status        int    `json:"status,omitempty"`
+	Result        string `json:"result"`
+	ContentLength int64  `json:"content_length"`
+	BodyHash      string `json:"body_hash,omitempty"`
+}
+
+// Snapshot of the responses for each URL checked in a run
+type snapshot struct {
+	Created string          `json:"created"`
+	Entries []snapshotEntry `json:"entries"`
+}
+
+// Key identifying the request for a URL between runs
+func (e *snapshotEntry) key() string {
+	return e.Method + " " + e.URL + " " + e.Profile
+}
+
+func newSnapshotEntry(r scanner.Result) snapshotEntry {
+	return snapshotEntry{
+		URL:           r.URL,
+		Method:        r.Method,
+		Profile:       r.Profile,
+		Status:        r.Status,
+		Result:        r.Result,
+		ContentLength: r.ContentLength,
+		BodyHash:      r.BodyHash,
+	}
+}
+
+// Reads a snapshot file returning the entries by key
+func readSnapshot(filename string) (map[string]snapshotEntry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open snapshot file: '%s'", filename)
+	}
+	defer f.Close()
+
+	var s snapshot
+	if err := json.NewDecoder(f).Decode(&s); err != nil {
+		return nil, fmt.Errorf("could not read snapshot file: '%s': %v", filename, err)
+	}
+	entries := make(map[string]snapshotEntry, len(s.Entries))
+	for _, e := range s.Entries {
+		entries[e.key()] = e
+	}
+	return entries, nil
+}
+
+// Reads the results output as JSON or NDJSON by a previous run returning the entries by key
+func readResults(filename string) (map[string]snapshotEntry, error) {
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, fmt.Errorf("could not open baseline run file: '%s'", filename)
+	}
+	defer f.Close()
+
+	r := bufio.NewReader(f)
+	dec := json.NewDecoder(r)
+	var results []scanner.Result
+	if first, err := firstByte(r); err == nil && first == '[' {
+		err = dec.Decode(&results)
+	} else {
+		// NDJSON has an object per line
+		for {
+			var res scanner.Result
+			if err = dec.Decode(&res); err != nil {
+				break
+			}
+			results = append(results, res)
+		}
+		if err == io.EOF {
+			err = nil
+		}
+	}
+	if err != nil {
+		return nil, fmt.Errorf("could not read baseline run file: '%s': %v", filename, err)
+	}
+
+	entries := make(map[string]snapshotEntry, len(results))
+	for _, res := range results {
+		e := newSnapshotEntry(res)
+		entries[e.key()] = e
+	}
+	return entries, nil
+}
+
+// Returns the first byte that is not whitespace without consuming it
+func firstByte(r *bufio.Reader) (byte, error) {
+	for {
+		b, err := r.Peek(1)
+		if err != nil {
+			return 0, err
+		}
+		if !unicode.IsSpace(rune(b[0])) {
+			return b[0], nil
+		}
+		r.Discard(1)
+	}
+}
+
+// Records each result and writes the snapshot to the file once closed
+// the entries are sorted so that snapshots of the same URLs can be diffed
+type snapshotWriter struct {
+	mu      sync.Mutex
+	w       resultWriter
+	f       *os.File
+	entries []snapshotEntry
+}
+
+func (s *snapshotWriter) Write(r scanner.Result) error {
+	s.mu.Lock()
+	s.entries = append(s.entries, newSnapshotEntry(r))
+	s.mu.Unlock()
+	return s.w.Write(r)
+}
+
+func (s *snapshotWriter) Close() error {
+	if err := s.w.Close(); err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.entries == nil {
+		s.entries = []snapshotEntry{}
+	}
+	sort.Slice(s.entries, func(i, j int) bool { return s.entries[i].key() < s.entries[j].key() })
+	enc := json.NewEncoder(s.f)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(snapshot{Created: time.Now().Format(time.RFC3339), Entries: s.entries}); err != nil {
+		return err
+	}
+	return s.f.Close()
+}
+
+// Returns the differences between the previous and current responses for a URL
+// bodies are compared by hash when both have one otherwise by content length
+func snapshotChanges(prev, cur snapshotEntry) []string {
+	var changes []string
+	if prev.Result != cur.Result {
+		changes = append(changes, fmt.Sprintf("result %s -> %s", prev.Result, cur.Result))
+	}
+	if prev.Status != cur.Status {
+		changes = append(changes, fmt.Sprintf("status %d -> %d", prev.Status, cur.Status))
+	}
+	if len(prev.BodyHash) > 0 && len(cur.BodyHash) > 0 {
+		if prev.BodyHash != cur.BodyHash {
+			changes = append(changes, "body")
+		}
+	} else if prev.ContentLength != cur.ContentLength {
+		changes = append(changes, fmt.Sprintf("length %d -> %d", prev.ContentLength, cur.ContentLength))
+	}
+	return changes
+}
+
+// Compares each result to the previous response for the URL marking it unexpected when
+// it changed or is new, once closed any URLs that were not checked again are reported
+type compareWriter struct {
+	mu       sync.Mutex
+	w        resultWriter
+	previous map[string]snapshotEntry
+	seen     map[string]struct{}
+}
+
+func newCompareWriter(w resultWriter, previous map[string]snapshotEntry) *compareWriter {
+	return &compareWriter{w: w, previous: previous, seen: map[string]struct{}{}}
+}
+
+func (c *compareWriter) Write(r scanner.Result) error {
+	cur := newSnapshotEntry(r)
+	c.mu.Lock()
+	c.seen[cur.key()] = struct{}{}
+	prev, ok := c.previous[cur.key()]
+	c.mu.Unlock()
+
+	if !ok {
+		r.Unexpected = true
+		r.Message = "NEW since the previous run: " + r.Message
+	} else if changes := snapshotChanges(prev, cur); len(changes) > 0 {
+		r.Unexpected = true
+		r.Message = fmt.Sprintf("CHANGED since the previous run (%s): %s", strings.Join(changes, ", "), r.Message)
+	}
+	return c.w.Write(r)
+}
+
+func (c *compareWriter) Close() error {
+	c.mu.Lock()
+	var removed []string
+	for key, e := range c.previous {
+		if _, ok := c.seen[key]; !ok {
+			removed = append(removed, fmt.Sprintf("%s %s", e.Method, e.URL))
+		}
+	}
+	c.mu.Unlock()
+	sort.Strings(removed)
+	for _, r := range removed {
+		fmt.Fprintf(os.Stderr, "[!] not checked since the previous run: %s\n", r)
+	}
+	return c.w.Close()
+}
@@ -0,0 +1,141 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Counted for results that differ from the expected result
+const resultUnexpected = "unexpected"
+
+// Counts of the results written during the run
+type stats struct {
+	mu    sync.Mutex
+	start time.Time
+	total int
+	// URLs skipped as duplicates
+	duplicates int
+	// URLs skipped as invalid
+	invalid  int
+	results  map[string]int
+	statuses map[int]int
+	// error results by the type of error
+	errors map[string]int
+}
+
+func newStats() *stats {
+	return &stats{
+		start:    time.Now(),
+		results:  map[string]int{},
+		statuses: map[int]int{},
+		errors:   map[string]int{},
+	}
+}
+
+// Adds the result to the counts
+func (s *stats) add(r scanner.Result) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.total++
+	s.results[r.Result]++
+	if r.Unexpected {
+		s.results[resultUnexpected]++
+	}
+	if len(r.Assertion) > 0 {
+		s.results[r.Assertion]++
+	}
+	if r.Status > 0 {
+		s.statuses[r.Status]++
+	}
+	if len(r.ErrorType) > 0 {
+		s.errors[r.ErrorType]++
+	}
+}
+
+// Counts a URL skipped as a duplicate
+func (s *stats) duplicate(scanner.Target) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.duplicates++
+}
+
+// Counts a URL skipped as invalid
+func (s *stats) invalidURL(scanner.Target) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invalid++
+}
+
+// Checks if there were any of the results
+func (s *stats) any(results ...string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range results {
+		if s.results[r] > 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// Writes the summary of the counts
+func (s *stats) print(w io.Writer) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fmt.Fprintln(w, "[*] Summary")
+	fmt.Fprintf(w, "    URLs:    %d\n", s.total)
+	fmt.Fprintf(w, "    Granted: %d\n", s.results[scanner.ResultGranted])
+	fmt.Fprintf(w, "    Denied:  %d\n", s.results[scanner.ResultDenied])
+	fmt.Fprintf(w, "    Errors:  %d\n", s.results[scanner.ResultError])
+	types := make([]string, 0, len(s.errors))
+	for t := range s.errors {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	for _, t := range types {
+		fmt.Fprintf(w, "      %s: %d\n", t, s.errors[t])
+	}
+	if s.results[resultUnexpected] > 0 {
+		fmt.Fprintf(w, "    Unexpected: %d\n", s.results[resultUnexpected])
+	}
+	if s.results[scanner.AssertionPass] > 0 || s.results[scanner.AssertionFail] > 0 {
+		fmt.Fprintf(w, "    Passed:  %d\n", s.results[scanner.AssertionPass])
+		fmt.Fprintf(w, "    Failed:  %d\n", s.results[scanner.AssertionFail])
+	}
+	if s.duplicates > 0 {
+		fmt.Fprintf(w, "    Duplicates skipped: %d\n", s.duplicates)
+	}
+	if s.invalid > 0 {
+		fmt.Fprintf(w, "    Invalid URLs skipped: %d\n", s.invalid)
+	}
+
+	codes := make([]int, 0, len(s.statuses))
+	for code := range s.statuses {
+		codes = append(codes, code)
+	}
+	sort.Ints(codes)
+	for _, code := range codes {
+		fmt.Fprintf(w, "    Status %d: %d\n", code, s.statuses[code])
+	}
+	fmt.Fprintf(w, "    Time:    %s\n", time.Since(s.start).Round(time.Millisecond))
+}
+
+// Adds each result to the stats before writing
+type statsWriter struct {
+	w     resultWriter
+	stats *stats
+}
+
+func (s *statsWriter) Write(r scanner.Result) error {
+	s.stats.add(r)
+	return s.w.Write(r)
+}
+
+func (s *statsWriter) Close() error {
+	return s.w.Close()
+}
@@ -1,6 +1,11 @@
 package utils
 
-import "sync"
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+)
 
 // splits the chan returned from f into separate work chans
 // num is the number of chans to split into f is the func that returns the chan
@@ -13,13 +18,29 @@ func Split[V any](num int, f func() chan V) []chan V {
 }
 
 // merges separate chans into a single chan
-func Merge[V any](chs ...chan V) chan V {
+// once ctx is done items are no longer forwarded but the chans are still drained
+// so that senders are not blocked, out is closed once all the chans are closed
+func Merge[V any](ctx context.Context, chs ...chan V) chan V {
+	return MergeBuffered(ctx, 0, chs...)
+}
+
+// merges separate chans into a single chan buffered by size
+// behaves the same as Merge otherwise, items dropped once ctx is done are not cleaned up
+// so items that need closing should be merged with a ctx that is not cancelled
+func MergeBuffered[V any](ctx context.Context, size int, chs ...chan V) chan V {
 	wg := sync.WaitGroup{}
 	wg.Add(len(chs))
-	out := make(chan V)
+	out := make(chan V, size)
 	send := func(c chan V) {
 		for n := range c {
-			out <- n
+			// checked first as select picks at random when out is also ready
+			if ctx.Err() != nil {
+				continue
+			}
+			select {
+			case out <- n:
+			case <-ctx.Done():
+			}
 		}
 		wg.Done()
 	}
@@ -35,3 +56,231 @@ func Merge[V any](chs ...chan V) chan V {
 
 	return out
 }
+
+// drops items from the chan that have already been seen
+// dropped is called with each duplicate and may be nil, out is closed once in is closed
+// or ctx is done
+func Dedupe[V comparable](ctx context.Context, in <-chan V, dropped func(V)) chan V {
+	return DedupeBy(ctx, in, func(v V) V { return v }, dropped)
+}
+
+// drops items from the chan with a key that has already been seen
+// only the keys are kept so a smaller key such as a hash can be used to bound memory
+func DedupeBy[V any, K comparable](ctx context.Context, in <-chan V, key func(V) K, dropped func(V)) chan V {
+	out := make(chan V)
+
+	go func() {
+		defer close(out)
+
+		seen := map[K]struct{}{}
+		for v := range in {
+			k := key(v)
+			if _, ok := seen[k]; ok {
+				if dropped != nil {
+					dropped(v)
+				}
+				continue
+			}
+			seen[k] = struct{}{}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// forwards only the items from the chan that pred returns true for
+// out is closed once in is closed or ctx is done
+func Filter[V any](ctx context.Context, in <-chan V, pred func(V) bool) chan V {
+	out := make(chan V)
+
+	go func() {
+		defer close(out)
+
+		for v := range in {
+			if !pred(v) {
+				continue
+			}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// transforms each item from the chan with f
+// out is closed once in is closed or ctx is done
+func Map[In, Out any](ctx context.Context, in <-chan In, f func(In) Out) chan Out {
+	out := make(chan Out)
+
+	go func() {
+		defer close(out)
+
+		for v := range in {
+			select {
+			case out <- f(v):
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+
+	return out
+}
+
+// merges separate chans into a single chan in the order of the index of each item
+// index must return each of 0, 1, 2... at most once, items that arrive before an earlier
+// index are held in memory until it arrives so this can buffer up to all the items
+// once ctx is done an earlier index may never arrive so the items held are sent in order
+// and any after are sent as they arrive, every item is sent so that it can be cleaned up
+// so out must be drained until it is closed once all the chans are closed
+func MergeOrdered[V any](ctx context.Context, size int, index func(V) uint64, chs ...chan V) chan V {
+	// merged without ctx so no items are dropped
+	merged := Merge(context.Background(), chs...)
+	out := make(chan V, size)
+
+	go func() {
+		defer close(out)
+
+		held := map[uint64]V{}
+		var next uint64
+		// sends the items held in the order of their index
+		flush := func() {
+			remaining := make([]uint64, 0, len(held))
+			for i := range held {
+				remaining = append(remaining, i)
+			}
+			sort.Slice(remaining, func(i, j int) bool { return remaining[i] < remaining[j] })
+			for _, i := range remaining {
+				out <- held[i]
+				delete(held, i)
+			}
+		}
+		for v := range merged {
+			if ctx.Err() != nil {
+				flush()
+				out <- v
+				continue
+			}
+			held[index(v)] = v
+			for {
+				v, ok := held[next]
+				if !ok {
+					break
+				}
+				delete(held, next)
+				next++
+				out <- v
+			}
+		}
+
+		// an index was never sent such as when cancelled so send what is left in order
+		flush()
+	}()
+
+	return out
+}
+
+// runs work on each item from the chan using n workers and sends each result on a single chan
+// once ctx is done no more items are worked on but every result of work is still sent so that
+// it can be cleaned up, out must be drained until it is closed once all the workers are done
+func WorkerPool[In, Out any](ctx context.Context, n int, in <-chan In, work func(In) Out) chan Out {
+	return WorkerPoolBuffered(ctx, 0, n, in, work)
+}
+
+// runs work on each item from the chan using n workers with the results chan buffered by size
+// behaves the same as WorkerPool otherwise
+func WorkerPoolBuffered[In, Out any](ctx context.Context, size, n int, in <-chan In, work func(In) Out) chan Out {
+	wg := sync.WaitGroup{}
+	wg.Add(n)
+	out := make(chan Out, size)
+	worker := func() {
+		defer wg.Done()
+		for v := range in {
+			if ctx.Err() != nil {
+				return
+			}
+			out <- work(v)
+		}
+	}
+
+	for i := 0; i < n; i++ {
+		go worker()
+	}
+
+	go func() {
+		wg.Wait()
+		close(out)
+	}()
+
+	return out
+}
+
+// forwards the items from the chan waiting at least every between each item
+// out is closed once in is closed or ctx is done
+func Throttle[V any](ctx context.Context, in <-chan V, every time.Duration) chan V {
+	out := make(chan V)
+
+	go func() {
+		defer close(out)
+
+		var last time.Time
+		for v := range in {
+			if wait := every - time.Since(last); wait > 0 {
+				t := time.NewTimer(wait)
+				select {
+				case <-t.C:
+				case <-ctx.Done():
+					t.Stop()
+					return
+				}
+			}
+			select {
+			case out <- v:
+			case <-ctx.Done():
+				return
+			}
+			last = time.Now()
+		}
+	}()
+
+	return out
+}
+
+// duplicates each item from the chan onto both of the returned chans
+// each item is sent on both chans before the next is read so the faster consumer is held
+// to the pace of the slower one, both chans must be drained until closed or ctx is done
+// both are closed once in is closed or ctx is done
+func Tee[V any](ctx context.Context, in <-chan V) (chan V, chan V) {
+	out1, out2 := make(chan V), make(chan V)
+
+	go func() {
+		defer close(out1)
+		defer close(out2)
+
+		for v := range in {
+			// a chan is set to nil once sent on so it is not selected again
+			o1, o2 := out1, out2
+			for o1 != nil || o2 != nil {
+				select {
+				case o1 <- v:
+					o1 = nil
+				case o2 <- v:
+					o2 = nil
+				case <-ctx.Done():
+					return
+				}
+			}
+		}
+	}()
+
+	return out1, out2
+}
@@ -0,0 +1,168 @@
+package utils
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// Returns a chan that is sent each of the items then closed
+func items[V any](vs ...V) chan V {
+	c := make(chan V)
+	go func() {
+		defer close(c)
+		for _, v := range vs {
+			c <- v
+		}
+	}()
+	return c
+}
+
+// Reads all the items from the chan until it is closed failing if it takes too long
+func collect[V any](t *testing.T, c <-chan V) []V {
+	t.Helper()
+	var out []V
+	timeout := time.After(5 * time.Second)
+	for {
+		select {
+		case v, ok := <-c:
+			if !ok {
+				return out
+			}
+			out = append(out, v)
+		case <-timeout:
+			t.Fatal("timed out waiting for the chan to close")
+		}
+	}
+}
+
+func TestFilter(t *testing.T) {
+	even := func(v int) bool { return v%2 == 0 }
+	tests := []struct {
+		name string
+		in   []int
+		want []int
+	}{
+		{"empty input", nil, nil},
+		{"all filtered", []int{1, 3, 5}, nil},
+		{"some filtered", []int{1, 2, 3, 4}, []int{2, 4}},
+		{"none filtered", []int{2, 4}, []int{2, 4}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := collect(t, Filter(context.Background(), items(tt.in...), even))
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("expected %v got %v", tt.want, got)
+			}
+		})
+	}
+}
+
+func TestFilterClosesWhenInputCloses(t *testing.T) {
+	in := make(chan int)
+	out := Filter(context.Background(), in, func(int) bool { return true })
+	in <- 1
+	if v := <-out; v != 1 {
+		t.Fatalf("expected 1 got %d", v)
+	}
+	close(in)
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("expected out to be closed")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("out was not closed once in was closed")
+	}
+}
+
+// Once cancelled the senders are not blocked even though out is not read
+func TestMergeCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	done := make(chan struct{})
+	chs := make([]chan int, 2)
+	for i := range chs {
+		chs[i] = make(chan int)
+		go func(c chan int) {
+			defer close(c)
+			for n := 0; n < 100; n++ {
+				c <- n
+			}
+			done <- struct{}{}
+		}(chs[i])
+	}
+	out := Merge(ctx, chs...)
+
+	for range chs {
+		select {
+		case <-done:
+		case <-time.After(5 * time.Second):
+			t.Fatal("sender blocked once the context was cancelled")
+		}
+	}
+	select {
+	case _, ok := <-out:
+		if ok {
+			t.Fatal("expected no items once the context was cancelled")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("out was not closed once the chans were closed")
+	}
+}
+
+// Items are spaced at least every apart, only a lower bound is checked so slow machines do not fail
+func TestThrottle(t *testing.T) {
+	const every = 20 * time.Millisecond
+	start := time.Now()
+	var times []time.Duration
+	for range Throttle(context.Background(), items(1, 2, 3, 4, 5), every) {
+		times = append(times, time.Since(start))
+	}
+	if len(times) != 5 {
+		t.Fatalf("expected 5 items got %d", len(times))
+	}
+	// allow for timer granularity on each wait
+	const slack = 2 * time.Millisecond
+	for i := 1; i < len(times); i++ {
+		if gap := times[i] - times[i-1]; gap < every-slack {
+			t.Errorf("expected at least %v between items %d and %d got %v", every, i, i+1, gap)
+		}
+	}
+	if total := times[len(times)-1]; total < 4*every-slack {
+		t.Errorf("expected 5 items to take at least %v got %v", 4*every, total)
+	}
+}
+
+func TestThrottleCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	out := Throttle(ctx, items(1, 2, 3), time.Hour)
+	if v := <-out; v != 1 {
+		t.Fatalf("expected 1 got %d", v)
+	}
+	cancel()
+	if got := collect(t, out); len(got) != 0 {
+		t.Errorf("expected no items once cancelled got %v", got)
+	}
+}
+
+// Once cancelled every item is still sent including those held waiting on an index that never arrives
+func TestMergeOrderedCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	// index 0 never arrives
+	out := MergeOrdered(ctx, 0, func(v int) uint64 { return uint64(v) }, items(3, 1), items(2))
+	got := collect(t, out)
+	if len(got) != 3 {
+		t.Errorf("expected all 3 items once cancelled got %v", got)
+	}
+}
+
+func TestMergeOrdered(t *testing.T) {
+	out := MergeOrdered(context.Background(), 0, func(v int) uint64 { return uint64(v) }, items(4, 1, 2), items(0, 3))
+	if got, want := collect(t, out), []int{0, 1, 2, 3, 4}; !reflect.DeepEqual(got, want) {
+		t.Errorf("expected %v got %v", want, got)
+	}
+}
@@ -0,0 +1,46 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"runtime/debug"
+
+	"github.com/jessevdk/go-flags"
+)
+
+// Set at build time with -ldflags "-X main.version=v1.2.0 -X main.commit=abc123 -X main.date=2024-01-01"
+var (
+	version = "dev"
+	commit  = "unknown"
+	date    = "unknown"
+)
+
+// Checks if the version was requested, this is checked before parsing the options
+// so that the URL args are not required
+func versionRequested(args []string) bool {
+	var pre struct {
+		Version bool `long:"version"`
+	}
+	flags.NewParser(&pre, flags.IgnoreUnknown).ParseArgs(args)
+	return pre.Version
+}
+
+// Writes the version, commit and build date, the module build info is used
+// for any not set at build time such as when installed with go install
+func printVersion(w io.Writer) {
+	v, c, d := version, commit, date
+	if info, ok := debug.ReadBuildInfo(); ok {
+		if v == "dev" && len(info.Main.Version) > 0 && info.Main.Version != "(devel)" {
+			v = info.Main.Version
+		}
+		for _, s := range info.Settings {
+			switch {
+			case s.Key == "vcs.revision" && c == "unknown":
+				c = s.Value
+			case s.Key == "vcs.time" && d == "unknown":
+				d = s.Value
+			}
+		}
+	}
+	fmt.Fprintf(w, "gowac %s (commit %s, built %s)\n", v, c, d)
+}
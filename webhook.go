@@ -0,0 +1,117 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/stavinski/gowac/scanner"
+)
+
+// Number of batches that can be waiting to be delivered before writes block
+const webhookQueueSize = 100
+
+// Posts results of the event types to a webhook as a JSON array, results are sent in
+// batches from a separate goroutine so that a slow webhook does not hold up the scan
+// failures to deliver are logged to stderr and do not stop the scan
+type webhookWriter struct {
+	mu     sync.Mutex
+	url    string
+	events []string
+	batch  int
+	// encodes a batch of results into the payload to post
+	encode func([]scanner.Result) ([]byte, error)
+	// minimum time between posts and when the last post was made
+	interval time.Duration
+	last     time.Time
+	client   *http.Client
+	pending  []scanner.Result
+	queue    chan []scanner.Result
+	done     chan struct{}
+}
+
+func newWebhookWriter(url string, events []string, batch int) *webhookWriter {
+	return startWebhookWriter(&webhookWriter{
+		url:    url,
+		events: events,
+		batch:  batch,
+		encode: func(results []scanner.Result) ([]byte, error) { return json.Marshal(results) },
+	})
+}
+
+// Starts delivering the batches written to the webhook
+func startWebhookWriter(w *webhookWriter) *webhookWriter {
+	w.client = &http.Client{Timeout: 10 * time.Second}
+	w.queue = make(chan []scanner.Result, webhookQueueSize)
+	w.done = make(chan struct{})
+	go w.deliver()
+	return w
+}
+
+// Posts each batch from the queue until it is closed
+func (w *webhookWriter) deliver() {
+	defer close(w.done)
+	for results := range w.queue {
+		w.wait()
+		payload, err := w.encode(results)
+		if err == nil {
+			err = w.post(payload)
+		}
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "[!] could not deliver %d results to webhook: %v\n", len(results), err)
+		}
+	}
+}
+
+// Waits until the interval since the last post has passed
+func (w *webhookWriter) wait() {
+	if wait := w.interval - time.Since(w.last); wait > 0 {
+		time.Sleep(wait)
+	}
+	w.last = time.Now()
+}
+
+func (w *webhookWriter) post(payload []byte) error {
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func (w *webhookWriter) Write(r scanner.Result) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, event := range w.events {
+		if r.Result == event {
+			w.pending = append(w.pending, r)
+			break
+		}
+	}
+	if len(w.pending) >= w.batch {
+		w.queue <- w.pending
+		w.pending = nil
+	}
+	return nil
+}
+
+// Sends any remaining results and waits for them to be delivered
+func (w *webhookWriter) Close() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if len(w.pending) > 0 {
+		w.queue <- w.pending
+		w.pending = nil
+	}
+	close(w.queue)
+	<-w.done
+	return nil
+}